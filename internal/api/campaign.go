@@ -0,0 +1,197 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"patreon-posts/internal/models"
+)
+
+// campaignFields are the campaign attributes requested when resolving a campaign
+const campaignFields = "name,vanity,url"
+
+var (
+	numericRe = regexp.MustCompile(`^\d+$`)
+	vanityRe  = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	// Post URLs end in the post ID, e.g. /posts/some-title-12345678
+	postIDRe = regexp.MustCompile(`(?:^|-)(\d+)$`)
+)
+
+// reservedPaths are first path segments on patreon.com that aren't creator pages
+var reservedPaths = map[string]bool{
+	"home": true, "search": true, "explore": true, "settings": true, "messages": true,
+	"notifications": true, "login": true, "signup": true, "api": true, "file": true,
+}
+
+// campaignRef is a parsed campaign lookup: exactly one field is set
+type campaignRef struct {
+	campaignID string
+	vanity     string
+	postID     string
+	userID     string
+}
+
+// parseCampaignInput recognizes a campaign ID, a bare vanity name, a creator page URL
+// (patreon.com/<vanity>, /c/<vanity>, /cw/<vanity>, /user?u=<id>) or a post URL
+func parseCampaignInput(input string) (campaignRef, error) {
+	input = strings.TrimSpace(input)
+	input = strings.TrimPrefix(input, "@")
+	if input == "" {
+		return campaignRef{}, fmt.Errorf("enter a campaign ID, creator name or Patreon URL")
+	}
+
+	if numericRe.MatchString(input) {
+		return campaignRef{campaignID: input}, nil
+	}
+
+	if !strings.Contains(input, "/") && !strings.Contains(strings.ToLower(input), "patreon.com") {
+		if !vanityRe.MatchString(input) {
+			return campaignRef{}, fmt.Errorf("%q is not a valid creator name", input)
+		}
+		return campaignRef{vanity: input}, nil
+	}
+
+	raw := input
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return campaignRef{}, fmt.Errorf("invalid URL %q: %w", input, err)
+	}
+	if host := strings.ToLower(parsed.Hostname()); host != "patreon.com" && !strings.HasSuffix(host, ".patreon.com") {
+		return campaignRef{}, fmt.Errorf("%q is not a Patreon URL", input)
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(parsed.Path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	// Localized URLs start with a language code, e.g. /en-GB/posts/...
+	if len(segments) > 1 && len(segments[0]) == 5 && segments[0][2] == '-' {
+		segments = segments[1:]
+	}
+	if len(segments) == 0 {
+		return campaignRef{}, fmt.Errorf("%q does not point at a creator or post", input)
+	}
+
+	switch strings.ToLower(segments[0]) {
+	case "posts":
+		if len(segments) > 1 {
+			if m := postIDRe.FindStringSubmatch(segments[1]); m != nil {
+				return campaignRef{postID: m[1]}, nil
+			}
+		}
+		return campaignRef{}, fmt.Errorf("could not find a post ID in %q", input)
+	case "user", "profile":
+		if u := parsed.Query().Get("u"); numericRe.MatchString(u) {
+			return campaignRef{userID: u}, nil
+		}
+		return campaignRef{}, fmt.Errorf("could not find a user ID in %q", input)
+	case "c", "cw":
+		if len(segments) > 1 && vanityRe.MatchString(segments[1]) {
+			return campaignRef{vanity: segments[1]}, nil
+		}
+	default:
+		if !reservedPaths[strings.ToLower(segments[0])] && vanityRe.MatchString(segments[0]) {
+			return campaignRef{vanity: segments[0]}, nil
+		}
+	}
+	return campaignRef{}, fmt.Errorf("%q does not point at a creator or post", input)
+}
+
+// ResolveCampaign finds the campaign for a campaign ID, creator vanity name, creator page URL
+// or post URL, returning its ID and creator name
+func (c *Client) ResolveCampaign(input string) (*models.Campaign, error) {
+	return c.ResolveCampaignContext(context.Background(), input)
+}
+
+// ResolveCampaignContext is like ResolveCampaign but aborts when ctx is cancelled
+func (c *Client) ResolveCampaignContext(ctx context.Context, input string) (*models.Campaign, error) {
+	ref, err := parseCampaignInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("fields[campaign]", campaignFields)
+	params.Set("json-api-use-default-includes", "false")
+	params.Set("json-api-version", "1.0")
+
+	switch {
+	case ref.campaignID != "":
+		endpoint := fmt.Sprintf("%s/campaigns/%s", c.baseURL, ref.campaignID)
+		return c.fetchCampaign(ctx, endpoint, params, "")
+
+	case ref.postID != "":
+		params.Set("include", "campaign")
+		params.Set("fields[post]", "title")
+		endpoint := fmt.Sprintf("%s/posts/%s", c.baseURL, ref.postID)
+		return c.fetchCampaign(ctx, endpoint, params, "campaign")
+
+	case ref.userID != "":
+		params.Set("include", "campaign")
+		params.Set("fields[user]", "full_name")
+		endpoint := fmt.Sprintf("%s/user/%s", c.baseURL, ref.userID)
+		return c.fetchCampaign(ctx, endpoint, params, "campaign")
+	}
+
+	// Vanity names are looked up with a filtered campaign list
+	params.Set("filter[vanity]", ref.vanity)
+	fullURL := fmt.Sprintf("%s/campaigns?%s", c.baseURL, params.Encode())
+	body, err := c.get(ctx, fullURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp models.ResourceListResponse
+	if err := decode(body, &resp); err != nil {
+		return nil, err
+	}
+	for _, resource := range resp.Data {
+		if resource.Type != "campaign" {
+			continue
+		}
+		campaign, err := models.FromCampaignResource(resource)
+		if err != nil {
+			return nil, &DecodeError{Body: truncate(string(body), 200), Err: err}
+		}
+		return &campaign, nil
+	}
+	return nil, fmt.Errorf("no creator named %q: %w", ref.vanity, ErrNotFound)
+}
+
+// fetchCampaign requests a single resource and returns the campaign it is or, when relationship
+// is set, the included campaign it points at
+func (c *Client) fetchCampaign(ctx context.Context, endpoint string, params url.Values, relationship string) (*models.Campaign, error) {
+	fullURL := fmt.Sprintf("%s?%s", endpoint, params.Encode())
+	body, err := c.get(ctx, fullURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp models.ResourceResponse
+	if err := decode(body, &resp); err != nil {
+		return nil, err
+	}
+
+	resource := resp.Data
+	if relationship != "" {
+		resolved := models.NewIncluded(resp.Included).ResolveAll(resp.Data.Relationships[relationship])
+		if len(resolved) == 0 {
+			return nil, fmt.Errorf("no campaign found: %w", ErrNotFound)
+		}
+		resource = resolved[0]
+	}
+
+	campaign, err := models.FromCampaignResource(resource)
+	if err != nil {
+		return nil, &DecodeError{Body: truncate(string(body), 200), Err: err}
+	}
+	return &campaign, nil
+}
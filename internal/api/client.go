@@ -1,50 +1,174 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strings"
+	"time"
 
+	"patreon-posts/internal/htmltext"
+	"patreon-posts/internal/links"
 	"patreon-posts/internal/models"
 )
 
-// YouTube URL patterns
-var youtubePatterns = []*regexp.Regexp{
-	regexp.MustCompile(`https?://(?:www\.)?youtube\.com/watch\?v=([a-zA-Z0-9_-]{11})`),
-	regexp.MustCompile(`https?://(?:www\.)?youtube\.com/embed/([a-zA-Z0-9_-]{11})`),
-	regexp.MustCompile(`https?://youtu\.be/([a-zA-Z0-9_-]{11})`),
-	regexp.MustCompile(`https?://(?:www\.)?youtube\.com/v/([a-zA-Z0-9_-]{11})`),
-	regexp.MustCompile(`https?://(?:www\.)?youtube\.com/shorts/([a-zA-Z0-9_-]{11})`),
+// DefaultBaseURL is the Patreon API endpoint used when no base URL is configured
+const DefaultBaseURL = "https://www.patreon.com/api"
+
+// DefaultUserAgent mimics a desktop browser, which Patreon expects for its web API
+const DefaultUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:146.0) Gecko/20100101 Firefox/146.0"
+
+// ClientOptions configures a Client
+type ClientOptions struct {
+	BaseURL     string            // API base URL (default: DefaultBaseURL)
+	Cookies     string            // Cookie header value the session starts from
+	CookieStore CookieStore       // Persists cookies updated by Patreon (default: updates last until exit)
+	Logger      *log.Logger       // Debug log for cookie changes (default: discarded)
+	Transport   http.RoundTripper // Custom transport (default: clone of http.DefaultTransport)
+	Timeout     time.Duration     // Overall timeout per API request, not file downloads (default: none; the app passes request_timeout_ms, 30s unless configured)
+	UserAgent   string            // User-Agent header (default: DefaultUserAgent)
+	ProxyURL    string            // HTTP(S) proxy URL; overrides environment proxy settings
+	Retry       *RetryPolicy      // Retry behaviour for transient failures (default: DefaultRetryPolicy)
+	RateLimit   *RateLimit        // Request spacing applied per host (default: DefaultRateLimit)
+	Links       *links.Registry   // Link extractors applied to post details (default: all builtin providers)
 }
 
-const baseURL = "https://www.patreon.com/api"
-
 // Client handles Patreon API requests
 type Client struct {
 	httpClient *http.Client
-	cookies    string
+	fileClient *http.Client // Shares the transport but has no overall timeout, so long downloads aren't cut off
+	baseURL    string
+	userAgent  string
+	retry      RetryPolicy
+	limiter    *rateLimiter
+	links      *links.Registry
+	jar        *sessionJar
 }
 
-// NewClient creates a new Patreon API client
-func NewClient(cookies string) *Client {
+// NewClient creates a new Patreon API client from the given options
+func NewClient(opts ClientOptions) (*Client, error) {
+	baseURL := strings.TrimRight(opts.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if _, err := url.ParseRequestURI(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %w", opts.BaseURL, err)
+	}
+
+	userAgent := opts.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	transport, err := buildTransport(opts.Transport, opts.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	retry := DefaultRetryPolicy()
+	if opts.Retry != nil {
+		retry = *opts.Retry
+	}
+
+	registry := opts.Links
+	if registry == nil {
+		registry = links.NewDefaultRegistry(nil)
+	}
+
+	rateLimit := DefaultRateLimit()
+	if opts.RateLimit != nil {
+		rateLimit = *opts.RateLimit
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	base, _ := url.Parse(baseURL)
+	jar := newSessionJar(base, opts.Cookies, opts.CookieStore, logger)
+
 	return &Client{
-		httpClient: &http.Client{},
-		cookies:    cookies,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   opts.Timeout,
+			Jar:       jar,
+		},
+		// Deliberately without Timeout: a large file can take longer than any API request
+		// should, and a stalled transfer is stopped by cancelling its context instead
+		fileClient: &http.Client{
+			Transport: transport,
+			Jar:       jar,
+		},
+		baseURL:   baseURL,
+		userAgent: userAgent,
+		jar:       jar,
+		retry:     retry,
+		limiter:   newRateLimiter(rateLimit),
+		links:     registry,
+	}, nil
+}
+
+// buildTransport applies the proxy setting to the given transport, falling back
+// to a clone of http.DefaultTransport when none is provided
+func buildTransport(rt http.RoundTripper, proxyURL string) (http.RoundTripper, error) {
+	if proxyURL == "" {
+		if rt == nil {
+			return http.DefaultTransport.(*http.Transport).Clone(), nil
+		}
+		return rt, nil
 	}
+
+	proxy, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %w", proxyURL, err)
+	}
+
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("proxy URL cannot be applied to custom transport %T", rt)
+	}
+	transport := base.Clone()
+	transport.Proxy = http.ProxyURL(proxy)
+	return transport, nil
+}
+
+// BaseURL returns the API base URL the client sends requests to
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// WithRateLimit returns a client that spaces its requests by limit instead. It shares this
+// client's connections, cookies and per-host request budget, so the two can be used together.
+func (c *Client) WithRateLimit(limit RateLimit) *Client {
+	clone := *c
+	clone.limiter = c.limiter.withPolicy(limit)
+	return &clone
+}
+
+// SetCookies replaces the session cookies with those in a Cookie header value
+func (c *Client) SetCookies(cookies string) {
+	c.jar.reset(cookies)
 }
 
 // FetchPosts retrieves posts for a given campaign ID with pagination support
 // cursor can be empty string or "null" for the first page
 func (c *Client) FetchPosts(campaignID string, count int, cursor string) (*models.PostsPage, error) {
-	endpoint := fmt.Sprintf("%s/campaigns/%s/posts", baseURL, campaignID)
+	return c.FetchPostsContext(context.Background(), campaignID, count, cursor)
+}
+
+// FetchPostsContext is like FetchPosts but aborts when ctx is cancelled
+func (c *Client) FetchPostsContext(ctx context.Context, campaignID string, count int, cursor string) (*models.PostsPage, error) {
+	endpoint := fmt.Sprintf("%s/campaigns/%s/posts", c.baseURL, campaignID)
 
 	params := url.Values{}
 	// Only request the fields we actually use
-	params.Set("fields[post]", "current_user_can_view,patreon_url,post_type,published_at,title")
+	params.Set("fields[post]", "commenter_count,current_user_can_view,patreon_url,post_type,published_at,title")
 	// No includes needed - we don't use any related data
 	params.Set("json-api-use-default-includes", "false")
 
@@ -65,32 +189,14 @@ func (c *Client) FetchPosts(campaignID string, count int, cursor string) (*model
 
 	fullURL := fmt.Sprintf("%s?%s", endpoint, params.Encode())
 
-	req, err := http.NewRequest("GET", fullURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	c.setHeaders(req)
-
-	resp, err := c.httpClient.Do(req)
+	body, err := c.get(ctx, fullURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	var patreonResp models.PatreonResponse
-	if err := json.Unmarshal(body, &patreonResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := decode(body, &patreonResp); err != nil {
+		return nil, err
 	}
 
 	posts := make([]models.Post, len(patreonResp.Data))
@@ -130,40 +236,31 @@ func extractCursorFromURL(nextURL string) string {
 
 // FetchPostDetails retrieves the full content of a single post
 func (c *Client) FetchPostDetails(postID string) (*models.PostDetails, error) {
-	endpoint := fmt.Sprintf("%s/posts/%s", baseURL, postID)
+	return c.FetchPostDetailsContext(context.Background(), postID)
+}
+
+// FetchPostDetailsContext is like FetchPostDetails but aborts when ctx is cancelled
+func (c *Client) FetchPostDetailsContext(ctx context.Context, postID string) (*models.PostDetails, error) {
+	endpoint := fmt.Sprintf("%s/posts/%s", c.baseURL, postID)
 
 	params := url.Values{}
 	params.Set("fields[post]", "content,embed,title,post_type,published_at,patreon_url")
+	// Include files attached to the post
+	params.Set("include", strings.Join(mediaRelationships, ","))
+	params.Set("fields[media]", "file_name,size_bytes,mimetype,download_url,image_urls")
+	params.Set("json-api-use-default-includes", "false")
 	params.Set("json-api-version", "1.0")
 
 	fullURL := fmt.Sprintf("%s?%s", endpoint, params.Encode())
 
-	req, err := http.NewRequest("GET", fullURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	c.setHeaders(req)
-
-	resp, err := c.httpClient.Do(req)
+	body, err := c.get(ctx, fullURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	var detailResp models.PostDetailResponse
-	if err := json.Unmarshal(body, &detailResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := decode(body, &detailResp); err != nil {
+		return nil, err
 	}
 
 	details := &models.PostDetails{
@@ -174,77 +271,84 @@ func (c *Client) FetchPostDetails(postID string) (*models.PostDetails, error) {
 		PublishedAt: detailResp.Data.Attributes.PublishedAt,
 	}
 
-	// Extract YouTube links from content and embed
-	allContent := details.Content
-	if detailResp.Data.Attributes.Embed.URL != "" {
-		allContent += " " + detailResp.Data.Attributes.Embed.URL
-	}
-	details.YouTubeLinks = ExtractYouTubeLinks(allContent)
+	// Render HTML as plain text for description, collecting its hyperlinks
+	doc := htmltext.Parse(details.Content)
+	details.Description = doc.Text()
+	details.Links = postLinks(doc, detailResp.Data.Attributes.Embed)
 
-	// Strip HTML for description
-	details.Description = stripHTML(details.Content)
+	// Extract provider links from content and embed
+	c.links.Apply(details)
 
-	return details, nil
-}
-
-// ExtractYouTubeLinks finds all YouTube video URLs in the given text
-func ExtractYouTubeLinks(content string) []string {
-	seen := make(map[string]bool)
-	var links []string
-
-	for _, pattern := range youtubePatterns {
-		matches := pattern.FindAllStringSubmatch(content, -1)
-		for _, match := range matches {
-			if len(match) >= 2 {
-				videoID := match[1]
-				fullURL := "https://www.youtube.com/watch?v=" + videoID
-				if !seen[videoID] {
-					seen[videoID] = true
-					links = append(links, fullURL)
-				}
-			}
-		}
+	// Resolve attached files from the included resources
+	media, err := postMedia(detailResp)
+	if err != nil {
+		return nil, err
 	}
+	details.Media = media
 
-	return links
+	return details, nil
 }
 
-// Pre-compiled regexes for HTML stripping
-var (
-	scriptTagRe  = regexp.MustCompile(`(?i)<script[^>]*>[\s\S]*?</script>`)
-	styleTagRe   = regexp.MustCompile(`(?i)<style[^>]*>[\s\S]*?</style>`)
-	htmlTagRe    = regexp.MustCompile(`<[^>]+>`)
-	whitespaceRe = regexp.MustCompile(`\s+`)
-)
-
-// stripHTML removes HTML tags and decodes entities
-func stripHTML(html string) string {
-	// Remove script tags with content
-	html = scriptTagRe.ReplaceAllString(html, "")
+// mediaRelationships are the post relationships that point at downloadable files, in display order
+var mediaRelationships = []string{"attachments_media", "images", "audio", "media"}
 
-	// Remove style tags with content
-	html = styleTagRe.ReplaceAllString(html, "")
+// mediaKinds maps each media relationship to the MediaFile kind it produces
+var mediaKinds = map[string]string{
+	"attachments_media": models.MediaKindAttachment,
+	"images":            models.MediaKindImage,
+	"audio":             models.MediaKindAudio,
+	"media":             models.MediaKindMedia,
+}
 
-	// Remove HTML tags
-	text := htmlTagRe.ReplaceAllString(html, "")
+// postMedia resolves the files a post links to through its media relationships.
+// The same file can appear under several relationships; it is listed once under the first.
+func postMedia(resp models.PostDetailResponse) ([]models.MediaFile, error) {
+	included := models.NewIncluded(resp.Included)
+	seen := make(map[string]bool)
+	var media []models.MediaFile
 
-	// Decode common HTML entities
-	text = strings.ReplaceAll(text, "&nbsp;", " ")
-	text = strings.ReplaceAll(text, "&amp;", "&")
-	text = strings.ReplaceAll(text, "&lt;", "<")
-	text = strings.ReplaceAll(text, "&gt;", ">")
-	text = strings.ReplaceAll(text, "&quot;", "\"")
-	text = strings.ReplaceAll(text, "&#39;", "'")
+	for _, rel := range mediaRelationships {
+		for _, resource := range included.ResolveAll(resp.Data.Relationships[rel]) {
+			if seen[resource.ID] {
+				continue
+			}
+			seen[resource.ID] = true
 
-	// Clean up whitespace
-	text = whitespaceRe.ReplaceAllString(text, " ")
-	text = strings.TrimSpace(text)
+			file, err := models.FromMediaResource(resource, mediaKinds[rel])
+			if err != nil {
+				return nil, &DecodeError{Body: truncate(string(resource.Attributes), 200), Err: err}
+			}
+			media = append(media, file)
+		}
+	}
+	return media, nil
+}
 
-	return text
+// postLinks lists the hyperlinks in the post body followed by the embed, if any
+func postLinks(doc *htmltext.Document, embed models.Embed) []models.Link {
+	var result []models.Link
+	seen := make(map[string]bool)
+	for _, link := range doc.Links {
+		result = append(result, models.Link{URL: link.URL, Text: link.Text, Source: models.LinkSourceContent})
+		seen[link.URL] = true
+	}
+	if embed.URL != "" && !seen[embed.URL] {
+		text := embed.Subject
+		if text == "" {
+			text = embed.Description
+		}
+		result = append(result, models.Link{
+			URL:      embed.URL,
+			Text:     text,
+			Source:   models.LinkSourceEmbed,
+			Provider: embed.Provider,
+		})
+	}
+	return result
 }
 
 func (c *Client) setHeaders(req *http.Request) {
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:146.0) Gecko/20100101 Firefox/146.0")
+	req.Header.Set("User-Agent", c.userAgent)
 	req.Header.Set("Accept", "*/*")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
 	// Note: Don't set Accept-Encoding manually - Go's http.Transport handles it automatically
@@ -254,8 +358,5 @@ func (c *Client) setHeaders(req *http.Request) {
 	req.Header.Set("Sec-Fetch-Dest", "empty")
 	req.Header.Set("Sec-Fetch-Mode", "cors")
 	req.Header.Set("Sec-Fetch-Site", "same-origin")
-
-	if c.cookies != "" {
-		req.Header.Set("Cookie", c.cookies)
-	}
+	// Cookies are added by the jar
 }
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"patreon-posts/internal/models"
+)
+
+// maxReplyDepth bounds how deeply nested replies are followed
+const maxReplyDepth = 5
+
+// FetchComments retrieves a page of a post's top-level comments with their replies.
+// cursor can be empty for the first page
+func (c *Client) FetchComments(postID, cursor string) (*models.CommentsPage, error) {
+	return c.FetchCommentsContext(context.Background(), postID, cursor)
+}
+
+// FetchCommentsContext is like FetchComments but aborts when ctx is cancelled
+func (c *Client) FetchCommentsContext(ctx context.Context, postID, cursor string) (*models.CommentsPage, error) {
+	endpoint := fmt.Sprintf("%s/posts/%s/comments", c.baseURL, postID)
+
+	params := url.Values{}
+	// Replies and their authors come back as included resources
+	params.Set("include", "commenter,replies,replies.commenter")
+	params.Set("fields[comment]", "body,created,deleted_at,is_by_creator,vote_sum,reply_count")
+	params.Set("fields[user]", "full_name,vanity")
+	params.Set("json-api-use-default-includes", "false")
+	params.Set("page[count]", "20")
+	if cursor != "" {
+		params.Set("page[cursor]", cursor)
+	}
+	params.Set("sort", "-created")
+	params.Set("json-api-version", "1.0")
+
+	fullURL := fmt.Sprintf("%s?%s", endpoint, params.Encode())
+
+	body, err := c.get(ctx, fullURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp models.CommentsResponse
+	if err := decode(body, &resp); err != nil {
+		return nil, err
+	}
+
+	included := models.NewIncluded(resp.Included)
+	comments := make([]models.Comment, 0, len(resp.Data))
+	for _, resource := range resp.Data {
+		comment, err := c.buildComment(resource, postID, "", included, 0)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+
+	return &models.CommentsPage{
+		Comments:   comments,
+		NextCursor: extractCursorFromURL(resp.Links.Next),
+		HasMore:    resp.Links.Next != "",
+	}, nil
+}
+
+// buildComment converts a comment resource into a Comment, resolving its author and replies
+func (c *Client) buildComment(
+	resource models.Resource,
+	postID, parentID string,
+	included models.Included,
+	depth int,
+) (models.Comment, error) {
+	var attrs models.CommentAttributes
+	if err := resource.DecodeAttributes(&attrs); err != nil {
+		return models.Comment{}, &DecodeError{Body: truncate(string(resource.Attributes), 200), Err: err}
+	}
+
+	comment := models.Comment{
+		ID:          resource.ID,
+		PostID:      postID,
+		ParentID:    parentID,
+		Body:        attrs.Body,
+		CreatedAt:   attrs.Created,
+		IsByCreator: attrs.IsByCreator,
+		VoteSum:     attrs.VoteSum,
+		ReplyCount:  attrs.ReplyCount,
+	}
+	if attrs.DeletedAt != nil {
+		comment.Body = "(deleted)"
+	} else {
+		comment.Links = c.links.Extract(attrs.Body)
+	}
+
+	for _, user := range included.ResolveAll(resource.Relationships["commenter"]) {
+		var userAttrs models.UserAttributes
+		if err := user.DecodeAttributes(&userAttrs); err == nil {
+			comment.Author = userAttrs.FullName
+			if comment.Author == "" {
+				comment.Author = userAttrs.Vanity
+			}
+		}
+	}
+
+	if depth < maxReplyDepth {
+		for _, reply := range included.ResolveAll(resource.Relationships["replies"]) {
+			child, err := c.buildComment(reply, postID, comment.ID, included, depth+1)
+			if err != nil {
+				return models.Comment{}, err
+			}
+			comment.Replies = append(comment.Replies, child)
+		}
+	}
+	return comment, nil
+}
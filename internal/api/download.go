@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileResponse is an open transfer of a post attachment or media file
+type FileResponse struct {
+	Body        io.ReadCloser // File contents starting at Offset; the caller must close it
+	Offset      int64         // Byte offset Body starts at; 0 when the server ignored the requested range
+	Size        int64         // Total size of the file, or -1 if the server did not report it
+	ContentType string        // Content-Type reported by the server
+}
+
+// OpenFile starts downloading a media file, resuming from offset when offset > 0 and
+// the server supports range requests. Requests share the client's rate limit and retry policy.
+func (c *Client) OpenFile(ctx context.Context, fileURL string, offset int64) (*FileResponse, error) {
+	var file *FileResponse
+	err := c.withRetry(ctx, func() (time.Duration, error) {
+		var retryAfter time.Duration
+		var err error
+		file, retryAfter, err = c.openFileOnce(ctx, fileURL, offset)
+		return retryAfter, err
+	})
+	return file, err
+}
+
+// openFileOnce performs a single download attempt, returning the Retry-After delay on failure if present
+func (c *Client) openFileOnce(ctx context.Context, fileURL string, offset int64) (*FileResponse, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fileURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Referer", "https://www.patreon.com/")
+	// The jar only sends session cookies to Patreon; media is often served from other hosts
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	if err := c.limiter.wait(ctx, req.URL.Host); err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := c.fileClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	file := &FileResponse{
+		Body:        resp.Body,
+		Size:        -1,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		file.Size = resp.ContentLength
+		return file, 0, nil
+
+	case http.StatusPartialContent:
+		start, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok {
+			resp.Body.Close()
+			return nil, 0, fmt.Errorf("invalid Content-Range %q", resp.Header.Get("Content-Range"))
+		}
+		file.Offset = start
+		file.Size = size
+		return file, 0, nil
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file already holds everything the server has
+		_, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if ok && size == offset {
+			resp.Body.Close()
+			file.Body = http.NoBody
+			file.Offset = offset
+			file.Size = size
+			return file, 0, nil
+		}
+	}
+
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	return nil, retryAfter, newAPIError(resp.StatusCode, body, retryAfter)
+}
+
+// parseContentRange decodes "bytes start-end/size" or "bytes */size", returning -1 for an unknown size
+func parseContentRange(value string) (start, size int64, ok bool) {
+	spec, found := strings.CutPrefix(value, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rangePart, sizePart, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, false
+	}
+
+	size = -1
+	if sizePart != "*" {
+		n, err := strconv.ParseInt(sizePart, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		size = n
+	}
+
+	if rangePart == "*" {
+		return 0, size, true
+	}
+	startPart, _, found := strings.Cut(rangePart, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(startPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
@@ -0,0 +1,32 @@
+package api
+
+import "testing"
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		value string
+		start int64
+		size  int64
+		ok    bool
+	}{
+		{"bytes 100-199/200", 100, 200, true},
+		{"bytes 0-9/*", 0, -1, true},
+		{"bytes */500", 0, 500, true},
+		{"bytes */*", 0, -1, true},
+		{"100-199/200", 0, 0, false},
+		{"bytes 100-199", 0, 0, false},
+		{"bytes x-199/200", 0, 0, false},
+		{"bytes 100-199/big", 0, 0, false},
+		{"", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			start, size, ok := parseContentRange(tt.value)
+			if start != tt.start || size != tt.size || ok != tt.ok {
+				t.Errorf("parseContentRange(%q) = %d, %d, %v, want %d, %d, %v",
+					tt.value, start, size, ok, tt.start, tt.size, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"patreon-posts/internal/models"
+)
+
+// Sentinel errors matched by errors.Is against errors returned from Client methods
+var (
+	ErrUnauthorized = errors.New("not logged in or session expired")
+	ErrForbidden    = errors.New("access forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited by Patreon")
+	ErrDecode       = errors.New("unexpected response format")
+)
+
+// APIError is returned when Patreon responds with a non-200 status
+type APIError struct {
+	StatusCode int                  // HTTP status of the final attempt
+	Body       string               // Raw response body of the final attempt
+	Errors     []models.ErrorObject // JSON:API errors[] parsed from the body, if any
+	Attempts   int                  // Number of attempts made before giving up
+	RetryAfter time.Duration        // Server-requested wait from the Retry-After header, if any
+}
+
+// newAPIError builds an APIError, parsing the JSON:API errors[] array when the body has one
+func newAPIError(statusCode int, body []byte, retryAfter time.Duration) *APIError {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Body:       string(body),
+		RetryAfter: retryAfter,
+	}
+	var errResp models.ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil {
+		apiErr.Errors = errResp.Errors
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("API returned status %d", e.StatusCode)
+	if e.Attempts > 1 {
+		msg += fmt.Sprintf(" after %d attempts", e.Attempts)
+	}
+	if detail := e.Detail(); detail != "" {
+		msg += ": " + detail
+	} else if e.Body != "" {
+		msg += ": " + truncate(e.Body, 200)
+	}
+	return msg
+}
+
+// Detail returns the human-readable messages from the parsed errors[] array
+func (e *APIError) Detail() string {
+	var parts []string
+	for _, obj := range e.Errors {
+		switch {
+		case obj.Detail != "":
+			parts = append(parts, obj.Detail)
+		case obj.Title != "":
+			parts = append(parts, obj.Title)
+		case obj.CodeName != "":
+			parts = append(parts, obj.CodeName)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Is lets errors.Is match an APIError against the sentinel errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// DecodeError is returned when a 200 response cannot be parsed, usually because Patreon changed its schema
+type DecodeError struct {
+	Body string // Start of the response body that failed to parse
+	Err  error  // Underlying JSON error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to parse response: %v", e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Is lets errors.Is match a DecodeError against ErrDecode
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+// decode unmarshals a response body, wrapping failures in a DecodeError
+func decode(body []byte, v any) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return &DecodeError{Body: truncate(string(body), 200), Err: err}
+	}
+	return nil
+}
+
+// truncate shortens s to at most n bytes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
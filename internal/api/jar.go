@@ -0,0 +1,157 @@
+package api
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// CookieStore persists the cookies Patreon rotates through Set-Cookie, so updated
+// session values survive restarts
+type CookieStore interface {
+	// LoadCookies returns the cookies saved for the session that started from the given Cookie header
+	LoadCookies(seed string) ([]*http.Cookie, error)
+	// SaveCookies records changed cookies for that session; cookies with MaxAge < 0 were removed
+	SaveCookies(seed string, cookies []*http.Cookie) error
+}
+
+// sessionJar is a cookie jar seeded from a Cookie header that reports and persists
+// changes to the cookies sent to the API
+type sessionJar struct {
+	base   *url.URL
+	store  CookieStore
+	logger *log.Logger
+
+	mu   sync.RWMutex
+	jar  *cookiejar.Jar
+	seed string // Cookie header the session started from
+}
+
+// newSessionJar creates a jar holding the cookies in header, updated with any saved in store
+func newSessionJar(base *url.URL, header string, store CookieStore, logger *log.Logger) *sessionJar {
+	j := &sessionJar{base: base, store: store, logger: logger}
+	j.reset(header)
+	return j
+}
+
+// reset replaces every cookie with those in header, updated with any saved in the store for it
+func (j *sessionJar) reset(header string) {
+	// cookiejar.New only fails for invalid options
+	jar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	jar.SetCookies(j.base, parseCookieHeader(header, seedDomain(j.base.Hostname())))
+
+	if j.store != nil && header != "" {
+		saved, err := j.store.LoadCookies(header)
+		if err != nil {
+			j.logger.Printf("cookies: failed to load saved cookies: %v", err)
+		} else if len(saved) > 0 {
+			jar.SetCookies(j.base, saved)
+			j.logger.Printf("cookies: restored %s", cookieNames(saved))
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.jar = jar
+	j.seed = header
+}
+
+// Cookies implements http.CookieJar
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.jar.Cookies(u)
+}
+
+// SetCookies implements http.CookieJar, saving any change to the cookies sent to the API
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	before := cookieValues(j.jar.Cookies(j.base))
+	j.jar.SetCookies(u, cookies)
+	after := cookieValues(j.jar.Cookies(j.base))
+	seed := j.seed
+	j.mu.Unlock()
+
+	var changed []*http.Cookie
+	for _, c := range cookies {
+		old, had := before[c.Name]
+		value, has := after[c.Name]
+		switch {
+		case has && !had:
+			j.logger.Printf("cookies: %s added by %s", c.Name, u.Host)
+		case has && value != old:
+			j.logger.Printf("cookies: %s updated by %s", c.Name, u.Host)
+		case had && !has:
+			j.logger.Printf("cookies: %s removed by %s", c.Name, u.Host)
+			changed = append(changed, &http.Cookie{Name: c.Name, Domain: c.Domain, Path: c.Path, MaxAge: -1})
+			continue
+		default:
+			continue
+		}
+		saved := *c
+		saved.Value = value
+		if c.MaxAge > 0 {
+			saved.Expires = time.Now().Add(time.Duration(c.MaxAge) * time.Second)
+		}
+		changed = append(changed, &saved)
+	}
+
+	if len(changed) > 0 && j.store != nil && seed != "" {
+		if err := j.store.SaveCookies(seed, changed); err != nil {
+			j.logger.Printf("cookies: failed to save updated cookies: %v", err)
+		}
+	}
+}
+
+// parseCookieHeader splits a Cookie header value into cookies scoped to domain,
+// or to the request host when domain is empty
+func parseCookieHeader(header, domain string) []*http.Cookie {
+	var cookies []*http.Cookie
+	for _, part := range strings.Split(header, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || name == "" {
+			continue
+		}
+		cookies = append(cookies, &http.Cookie{Name: name, Value: value, Domain: domain, Path: "/"})
+	}
+	return cookies
+}
+
+// seedDomain returns the domain configured cookies are scoped to, so they reach every
+// subdomain the way Patreon's own cookies do. Hosts without one, such as IPs or
+// localhost, get host-only cookies.
+func seedDomain(host string) string {
+	if net.ParseIP(host) != nil {
+		return ""
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return ""
+	}
+	return domain
+}
+
+// cookieValues maps cookie names to values
+func cookieValues(cookies []*http.Cookie) map[string]string {
+	values := make(map[string]string, len(cookies))
+	for _, c := range cookies {
+		values[c.Name] = c.Value
+	}
+	return values
+}
+
+// cookieNames lists cookie names for log messages, which must never include values
+func cookieNames(cookies []*http.Cookie) string {
+	names := make([]string, len(cookies))
+	for i, c := range cookies {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ", ")
+}
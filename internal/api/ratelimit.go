@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RateLimit configures the politeness policy applied to every request the client makes
+type RateLimit struct {
+	MinDelay time.Duration // Minimum spacing between requests to a host once the burst is used up
+	MaxDelay time.Duration // Maximum spacing; each interval is jittered between MinDelay and MaxDelay
+	Burst    int           // Requests allowed back-to-back per host before spacing applies (default: 1)
+}
+
+// DefaultRateLimit returns the rate limit used when none is configured
+func DefaultRateLimit() RateLimit {
+	return RateLimit{
+		MinDelay: 1 * time.Second,
+		MaxDelay: 3 * time.Second,
+		Burst:    1,
+	}
+}
+
+// interval returns a jittered refill interval between MinDelay and MaxDelay
+func (r RateLimit) interval() time.Duration {
+	if r.MaxDelay <= r.MinDelay {
+		return r.MinDelay
+	}
+	return r.MinDelay + time.Duration(rand.Int63n(int64(r.MaxDelay-r.MinDelay)))
+}
+
+// rateLimiter is a token bucket per host whose refill intervals are individually jittered
+type rateLimiter struct {
+	policy RateLimit
+	state  *limiterState // Shared with limiters derived by withPolicy
+}
+
+// limiterState holds the buckets of a limiter and the limiters derived from it
+type limiterState struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket tracks the request budget for a single host
+type bucket struct {
+	tokens     int
+	nextRefill time.Time
+}
+
+func newRateLimiter(policy RateLimit) *rateLimiter {
+	l := &rateLimiter{state: &limiterState{buckets: make(map[string]*bucket)}}
+	return l.withPolicy(policy)
+}
+
+// withPolicy returns a limiter that spaces requests by policy but draws from the same
+// buckets, so requests made under either policy count against the host's budget
+func (l *rateLimiter) withPolicy(policy RateLimit) *rateLimiter {
+	if policy.Burst < 1 {
+		policy.Burst = 1
+	}
+	return &rateLimiter{policy: policy, state: l.state}
+}
+
+// wait blocks until a request to host is allowed or ctx is cancelled
+func (l *rateLimiter) wait(ctx context.Context, host string) error {
+	for {
+		delay := l.reserve(host, time.Now())
+		if delay <= 0 {
+			return nil
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token for host if one is available, otherwise returns how long until the next refill
+func (l *rateLimiter) reserve(host string, now time.Time) time.Duration {
+	l.state.mu.Lock()
+	defer l.state.mu.Unlock()
+
+	b, ok := l.state.buckets[host]
+	if !ok {
+		b = &bucket{tokens: l.policy.Burst}
+		l.state.buckets[host] = b
+	}
+	if b.tokens > l.policy.Burst {
+		// Left over from a policy with a larger burst
+		b.tokens = l.policy.Burst
+	}
+
+	for b.tokens < l.policy.Burst && !now.Before(b.nextRefill) {
+		b.tokens++
+		b.nextRefill = b.nextRefill.Add(l.policy.interval())
+	}
+
+	if b.tokens == 0 {
+		return b.nextRefill.Sub(now)
+	}
+
+	// The refill clock starts when a full bucket is first drawn from
+	if b.tokens == l.policy.Burst {
+		b.nextRefill = now.Add(l.policy.interval())
+	}
+	b.tokens--
+	return 0
+}
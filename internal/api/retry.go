@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how transient failures are retried
+type RetryPolicy struct {
+	MaxRetries int           // Retries after the first attempt (0 disables retrying)
+	BaseDelay  time.Duration // Backoff before the first retry, doubled on each subsequent retry
+	MaxDelay   time.Duration // Upper bound for a single backoff or Retry-After wait
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  2 * time.Second,
+		MaxDelay:   60 * time.Second,
+	}
+}
+
+// backoff returns a jittered exponential delay for the given retry (0-indexed)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.BaseDelay << retry
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// Full jitter over the upper half keeps retries spread out without collapsing to zero
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// get performs a GET request, retrying transient failures, and returns the response body
+func (c *Client) get(ctx context.Context, fullURL string) ([]byte, error) {
+	var body []byte
+	err := c.withRetry(ctx, func() (time.Duration, error) {
+		var retryAfter time.Duration
+		var err error
+		body, retryAfter, err = c.getOnce(ctx, fullURL)
+		return retryAfter, err
+	})
+	return body, err
+}
+
+// withRetry calls attempt until it succeeds, fails with a non-retryable error or the
+// retry policy is exhausted. attempt returns the server's Retry-After delay on failure, if any.
+func (c *Client) withRetry(ctx context.Context, attempt func() (time.Duration, error)) error {
+	attempts := 0
+	for {
+		attempts++
+		retryAfter, err := attempt()
+		if err == nil {
+			return nil
+		}
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			apiErr.Attempts = attempts
+		}
+
+		retry := attempts - 1
+		if retry >= c.retry.MaxRetries || !isRetryable(err) {
+			if apiErr == nil && attempts > 1 {
+				return fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+			}
+			return err
+		}
+
+		wait := c.retry.backoff(retry)
+		if retryAfter > 0 {
+			// Waiting longer than the policy allows would stall the caller, so give up instead
+			if retryAfter > c.retry.MaxDelay {
+				return err
+			}
+			wait = retryAfter
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for d or until ctx is cancelled, whichever comes first
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// getOnce performs a single GET attempt, returning the Retry-After delay on failure if present
+func (c *Client) getOnce(ctx context.Context, fullURL string) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setHeaders(req)
+
+	if err := c.limiter.wait(ctx, req.URL.Host); err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return nil, retryAfter, newAPIError(resp.StatusCode, body, retryAfter)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, 0, nil
+}
+
+// isRetryable reports whether a failed attempt is worth repeating
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// parseRetryAfter decodes a Retry-After header given as seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if d := when.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
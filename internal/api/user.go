@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"patreon-posts/internal/models"
+)
+
+// FetchCurrentUser retrieves the account the session cookies belong to, along with its memberships.
+// An anonymous or expired session fails with an error matching ErrUnauthorized.
+func (c *Client) FetchCurrentUser() (*models.User, error) {
+	return c.FetchCurrentUserContext(context.Background())
+}
+
+// FetchCurrentUserContext is like FetchCurrentUser but aborts when ctx is cancelled
+func (c *Client) FetchCurrentUserContext(ctx context.Context) (*models.User, error) {
+	endpoint := fmt.Sprintf("%s/current_user", c.baseURL)
+
+	params := url.Values{}
+	params.Set("include", "memberships,memberships.campaign,memberships.currently_entitled_tiers")
+	params.Set("fields[user]", "full_name,vanity,email")
+	params.Set("fields[member]", "patron_status,currently_entitled_amount_cents")
+	params.Set("fields[campaign]", campaignFields)
+	params.Set("fields[tier]", "title,amount_cents")
+	params.Set("json-api-use-default-includes", "false")
+	params.Set("json-api-version", "1.0")
+
+	fullURL := fmt.Sprintf("%s?%s", endpoint, params.Encode())
+
+	body, err := c.get(ctx, fullURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp models.ResourceResponse
+	if err := decode(body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.ID == "" {
+		// Some anonymous sessions get an empty document rather than a 401
+		return nil, &APIError{StatusCode: http.StatusUnauthorized, Body: truncate(string(body), 200)}
+	}
+
+	var attrs models.CurrentUserAttributes
+	if err := resp.Data.DecodeAttributes(&attrs); err != nil {
+		return nil, &DecodeError{Body: truncate(string(body), 200), Err: err}
+	}
+
+	memberships, err := parseMemberships(resp)
+	if err != nil {
+		return nil, &DecodeError{Body: truncate(string(body), 200), Err: err}
+	}
+
+	return &models.User{
+		ID:          resp.Data.ID,
+		FullName:    attrs.FullName,
+		Vanity:      attrs.Vanity,
+		Email:       attrs.Email,
+		Memberships: memberships,
+	}, nil
+}
+
+// FetchMemberships retrieves the current user's memberships with their campaigns and tiers
+func (c *Client) FetchMemberships() ([]models.Membership, error) {
+	return c.FetchMembershipsContext(context.Background())
+}
+
+// FetchMembershipsContext is like FetchMemberships but aborts when ctx is cancelled
+func (c *Client) FetchMembershipsContext(ctx context.Context) ([]models.Membership, error) {
+	user, err := c.FetchCurrentUserContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return user.Memberships, nil
+}
+
+// parseMemberships resolves the user's member resources into Memberships,
+// skipping any whose campaign wasn't included
+func parseMemberships(resp models.ResourceResponse) ([]models.Membership, error) {
+	included := models.NewIncluded(resp.Included)
+
+	var memberships []models.Membership
+	for _, member := range included.ResolveAll(resp.Data.Relationships["memberships"]) {
+		var attrs models.MemberAttributes
+		if err := member.DecodeAttributes(&attrs); err != nil {
+			return nil, err
+		}
+
+		campaigns := included.ResolveAll(member.Relationships["campaign"])
+		if len(campaigns) == 0 {
+			continue
+		}
+		campaign, err := models.FromCampaignResource(campaigns[0])
+		if err != nil {
+			return nil, err
+		}
+
+		membership := models.Membership{
+			Campaign:    campaign,
+			Status:      attrs.PatronStatus,
+			PledgeCents: attrs.CurrentlyEntitledAmountCents,
+		}
+		// Several tiers can be entitled at once; the highest one describes the pledge best
+		highest := -1
+		for _, tier := range included.ResolveAll(member.Relationships["currently_entitled_tiers"]) {
+			var tierAttrs models.TierAttributes
+			if err := tier.DecodeAttributes(&tierAttrs); err != nil {
+				return nil, err
+			}
+			if tierAttrs.AmountCents > highest {
+				highest = tierAttrs.AmountCents
+				membership.Tier = tierAttrs.Title
+			}
+		}
+		memberships = append(memberships, membership)
+	}
+	return memberships, nil
+}
@@ -0,0 +1,179 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"patreon-posts/internal/db"
+	"patreon-posts/internal/download"
+	"patreon-posts/internal/models"
+)
+
+// Format selects how archived posts are written
+type Format string
+
+const (
+	Markdown Format = "markdown"
+	HTML     Format = "html"
+)
+
+// ParseFormat converts a format name such as "md" or "html" into a Format
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "", "md", "markdown":
+		return Markdown, nil
+	case "html", "htm":
+		return HTML, nil
+	}
+	return "", fmt.Errorf("unknown archive format %q, expected markdown or html", name)
+}
+
+// ext returns the file extension used for the format
+func (f Format) ext() string {
+	if f == HTML {
+		return ".html"
+	}
+	return ".md"
+}
+
+// Post is a cached post ready to be archived
+type Post struct {
+	Details    *models.PostDetails
+	PatreonURL string
+}
+
+// FromCached builds a Post from a database row
+func FromCached(cached *db.CachedPost) Post {
+	patreonURL := cached.PatreonURL
+	if strings.HasPrefix(patreonURL, "/") {
+		// Patreon returns post URLs relative to the site
+		patreonURL = "https://www.patreon.com" + patreonURL
+	}
+	return Post{Details: cached.Details(), PatreonURL: patreonURL}
+}
+
+// Archiver writes posts and index pages into a per-campaign directory tree
+type Archiver struct {
+	root   string
+	format Format
+}
+
+// New creates an Archiver that writes files of the given format under root
+func New(root string, format Format) *Archiver {
+	return &Archiver{root: root, format: format}
+}
+
+// CampaignDir returns the directory a campaign's posts are archived in
+func (a *Archiver) CampaignDir(campaignID, campaignName string) string {
+	return filepath.Join(a.root, download.CampaignDirName(campaignID, campaignName))
+}
+
+// WritePost archives a single post and returns the path written
+func (a *Archiver) WritePost(campaignID, campaignName string, post Post) (string, error) {
+	var content string
+	switch a.format {
+	case HTML:
+		html, err := renderPostHTML(campaignName, post)
+		if err != nil {
+			return "", err
+		}
+		content = html
+	default:
+		content = renderPostMarkdown(post)
+	}
+
+	path := filepath.Join(a.CampaignDir(campaignID, campaignName), postFileName(post, a.format))
+	if err := writeFile(path, content); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// WriteIndex writes the campaign's index page linking every archived post, newest first
+func (a *Archiver) WriteIndex(campaignID, campaignName string, posts []Post) (string, error) {
+	title := campaignName
+	if title == "" {
+		title = "Campaign " + campaignID
+	}
+
+	var content string
+	switch a.format {
+	case HTML:
+		html, err := renderIndexHTML(title, posts, a.format)
+		if err != nil {
+			return "", err
+		}
+		content = html
+	default:
+		content = renderIndexMarkdown(title, posts, a.format)
+	}
+
+	path := filepath.Join(a.CampaignDir(campaignID, campaignName), "index"+a.format.ext())
+	if err := writeFile(path, content); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// postFileName returns the file name a post is archived under
+func postFileName(post Post, format Format) string {
+	return download.PostName(post.Details) + format.ext()
+}
+
+// frontMatterLinks lists every link in the post once: hyperlinks and embeds first, then provider links
+func frontMatterLinks(details *models.PostDetails) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, link := range details.Links {
+		if !seen[link.URL] {
+			seen[link.URL] = true
+			urls = append(urls, link.URL)
+		}
+	}
+	for _, link := range details.ProviderLinks {
+		if !seen[link.URL] {
+			seen[link.URL] = true
+			urls = append(urls, link.URL)
+		}
+	}
+	return urls
+}
+
+// formatDate renders a publish date for display, or "unknown date" when missing
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "unknown date"
+	}
+	return t.Format("2006-01-02")
+}
+
+// writeFile replaces path atomically so an interrupted run never leaves a truncated page
+func writeFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".archive-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package archive
+
+import (
+	"html/template"
+	"strings"
+	"time"
+
+	"patreon-posts/internal/htmltext"
+	"patreon-posts/internal/models"
+)
+
+// pageStyle is inlined into every page so the archive needs no external files
+const pageStyle = `
+body { font-family: system-ui, sans-serif; max-width: 46rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.6; color: #222; }
+header { border-bottom: 1px solid #ddd; margin-bottom: 1.5rem; }
+.meta { color: #666; font-size: 0.9rem; }
+blockquote { border-left: 3px solid #ccc; margin-left: 0; padding-left: 1rem; color: #555; }
+pre { background: #f5f5f5; padding: 0.75rem; overflow-x: auto; }
+.list-item { margin: 0.2rem 0; }
+.ref { color: #666; font-size: 0.8rem; }
+a { color: #c0392b; word-break: break-all; }
+`
+
+// htmlBlock is a block of post content prepared for the page template
+type htmlBlock struct {
+	Kind   string // heading, item, quote, pre, rule or paragraph
+	Level  int    // HTML heading level, or list nesting depth
+	Marker string
+	Lines  []string
+}
+
+// Indent returns the left margin for a nested list item in rem
+func (b htmlBlock) Indent() int {
+	return (b.Level - 1) * 2
+}
+
+var postTemplate = template.Must(template.New("post").Funcs(template.FuncMap{
+	"summary": func(file models.MediaFile) string {
+		return mediaSummary(file.Kind, file.SizeBytes, file.MimeType)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="patreon:id" content="{{.Details.ID}}">
+<meta name="patreon:published_at" content="{{.PublishedAt}}">
+<meta name="patreon:url" content="{{.PatreonURL}}">
+{{- range .FrontMatterLinks}}
+<meta name="patreon:link" content="{{.}}">
+{{- end}}
+<title>{{.Details.Title}}</title>
+<style>{{.Style}}</style>
+</head>
+<body>
+<header>
+<p class="meta"><a href="index.html">{{.Campaign}}</a></p>
+<h1>{{.Details.Title}}</h1>
+<p class="meta">{{.Date}}{{if .Details.PostType}} · {{.Details.PostType}}{{end}}{{if .PatreonURL}} · <a href="{{.PatreonURL}}">original post</a>{{end}}</p>
+</header>
+<main>
+{{- if .Description}}
+<p>{{range $i, $line := .Description}}{{if $i}}<br>{{end}}{{$line}}{{end}}</p>
+{{- end}}
+{{- range .Blocks}}
+{{- if eq .Kind "heading"}}
+{{- if eq .Level 2}}<h2>{{else if eq .Level 3}}<h3>{{else if eq .Level 4}}<h4>{{else if eq .Level 5}}<h5>{{else}}<h6>{{end}}{{index .Lines 0}}{{if eq .Level 2}}</h2>{{else if eq .Level 3}}</h3>{{else if eq .Level 4}}</h4>{{else if eq .Level 5}}</h5>{{else}}</h6>{{end}}
+{{- else if eq .Kind "item"}}
+<div class="list-item" style="margin-left: {{.Indent}}rem">{{.Marker}} {{range $i, $line := .Lines}}{{if $i}}<br>{{end}}{{$line}}{{end}}</div>
+{{- else if eq .Kind "quote"}}
+<blockquote>{{range $i, $line := .Lines}}{{if $i}}<br>{{end}}{{$line}}{{end}}</blockquote>
+{{- else if eq .Kind "pre"}}
+<pre>{{range $i, $line := .Lines}}{{if $i}}
+{{end}}{{$line}}{{end}}</pre>
+{{- else if eq .Kind "rule"}}
+<hr>
+{{- else}}
+<p>{{range $i, $line := .Lines}}{{if $i}}<br>{{end}}{{$line}}{{end}}</p>
+{{- end}}
+{{- end}}
+</main>
+{{- if .Footnotes}}
+<h2>Links</h2>
+<ol>
+{{- range .Footnotes}}
+<li><a href="{{.URL}}">{{.URL}}</a>{{if .Text}} <span class="ref">{{.Text}}</span>{{end}}</li>
+{{- end}}
+</ol>
+{{- end}}
+{{- if .Details.Media}}
+<h2>Attachments</h2>
+<ul>
+{{- range .Details.Media}}
+<li>{{if .DownloadURL}}<a href="{{.DownloadURL}}">{{.FileName}}</a>{{else}}{{.FileName}}{{end}} <span class="meta">({{summary .}})</span></li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>{{.Style}}</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<p class="meta">{{len .Entries}} archived post(s), newest first.</p>
+</header>
+<ul>
+{{- range .Entries}}
+<li><span class="meta">{{.Date}}</span> <a href="{{.Href}}">{{.Title}}</a>{{if .PatreonURL}} <a class="meta" href="{{.PatreonURL}}">(original)</a>{{end}}</li>
+{{- end}}
+</ul>
+</body>
+</html>
+`))
+
+// renderPostHTML renders a post as a self-contained HTML page with its metadata in <meta> tags
+func renderPostHTML(campaignName string, post Post) (string, error) {
+	details := post.Details
+	doc := htmltext.Parse(details.Content)
+
+	data := struct {
+		Details          *models.PostDetails
+		Campaign         string
+		PatreonURL       string
+		PublishedAt      string
+		Date             string
+		FrontMatterLinks []string
+		Description      []string
+		Blocks           []htmlBlock
+		Footnotes        []htmltext.Link
+		Style            template.CSS
+	}{
+		Details:          details,
+		Campaign:         campaignName,
+		PatreonURL:       post.PatreonURL,
+		Date:             formatDate(details.PublishedAt),
+		FrontMatterLinks: frontMatterLinks(details),
+		Blocks:           htmlBlocks(doc),
+		Footnotes:        doc.Links,
+		Style:            template.CSS(pageStyle),
+	}
+	if data.Campaign == "" {
+		data.Campaign = "Index"
+	}
+	if !details.PublishedAt.IsZero() {
+		data.PublishedAt = details.PublishedAt.Format(time.RFC3339)
+	}
+	if len(doc.Blocks) == 0 && details.Description != "" {
+		// Only the plain-text description is cached
+		data.Description = strings.Split(details.Description, "\n")
+	}
+
+	var b strings.Builder
+	if err := postTemplate.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// htmlBlocks prepares parsed post content for the page template
+func htmlBlocks(doc *htmltext.Document) []htmlBlock {
+	blocks := make([]htmlBlock, 0, len(doc.Blocks))
+	for _, block := range doc.Blocks {
+		hb := htmlBlock{Level: block.Level, Marker: block.Marker, Lines: strings.Split(block.Text, "\n")}
+		switch block.Kind {
+		case htmltext.Heading:
+			// The post title is the page's only <h1>
+			hb.Kind = "heading"
+			hb.Level = min(block.Level+1, 6)
+			hb.Lines = []string{strings.ReplaceAll(block.Text, "\n", " ")}
+		case htmltext.ListItem:
+			hb.Kind = "item"
+		case htmltext.Quote:
+			hb.Kind = "quote"
+		case htmltext.Preformatted:
+			hb.Kind = "pre"
+		case htmltext.Rule:
+			hb.Kind = "rule"
+		default:
+			hb.Kind = "paragraph"
+		}
+		blocks = append(blocks, hb)
+	}
+	return blocks
+}
+
+// renderIndexHTML renders a campaign's index page as HTML
+func renderIndexHTML(title string, posts []Post, format Format) (string, error) {
+	type entry struct {
+		Date, Title, Href, PatreonURL string
+	}
+	entries := make([]entry, 0, len(posts))
+	for _, post := range posts {
+		entries = append(entries, entry{
+			Date:       formatDate(post.Details.PublishedAt),
+			Title:      post.Details.Title,
+			Href:       postFileName(post, format),
+			PatreonURL: post.PatreonURL,
+		})
+	}
+
+	data := struct {
+		Title   string
+		Entries []entry
+		Style   template.CSS
+	}{title, entries, template.CSS(pageStyle)}
+
+	var b strings.Builder
+	if err := indexTemplate.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,148 @@
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"patreon-posts/internal/download"
+	"patreon-posts/internal/htmltext"
+)
+
+// renderPostMarkdown renders a post as Markdown with YAML front matter
+func renderPostMarkdown(post Post) string {
+	details := post.Details
+	var b strings.Builder
+
+	// Front matter values are JSON-quoted, which is valid YAML
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "id: %s\n", yamlString(details.ID))
+	fmt.Fprintf(&b, "title: %s\n", yamlString(details.Title))
+	if !details.PublishedAt.IsZero() {
+		fmt.Fprintf(&b, "published_at: %s\n", yamlString(details.PublishedAt.Format(time.RFC3339)))
+	}
+	if post.PatreonURL != "" {
+		fmt.Fprintf(&b, "patreon_url: %s\n", yamlString(post.PatreonURL))
+	}
+	if details.PostType != "" {
+		fmt.Fprintf(&b, "post_type: %s\n", yamlString(details.PostType))
+	}
+	if links := frontMatterLinks(details); len(links) > 0 {
+		b.WriteString("links:\n")
+		for _, link := range links {
+			fmt.Fprintf(&b, "  - %s\n", yamlString(link))
+		}
+	}
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s\n\n", details.Title)
+
+	doc := htmltext.Parse(details.Content)
+	if len(doc.Blocks) == 0 && details.Description != "" {
+		// Only the plain-text description is cached
+		b.WriteString(details.Description)
+		b.WriteString("\n")
+	}
+	for i, block := range doc.Blocks {
+		if i > 0 && !(block.Kind == htmltext.ListItem && doc.Blocks[i-1].Kind == htmltext.ListItem) {
+			b.WriteString("\n")
+		}
+		b.WriteString(markdownBlock(block))
+		b.WriteString("\n")
+	}
+
+	if len(doc.Links) > 0 {
+		b.WriteString("\n## Links\n\n")
+		for i, link := range doc.Links {
+			fmt.Fprintf(&b, "- [%d] <%s>\n", i+1, link.URL)
+		}
+	}
+
+	if len(details.Media) > 0 {
+		b.WriteString("\n## Attachments\n\n")
+		for _, file := range details.Media {
+			name := file.FileName
+			if name == "" {
+				name = file.Kind + " " + file.ID
+			}
+			fmt.Fprintf(&b, "- %s (%s)", name, mediaSummary(file.Kind, file.SizeBytes, file.MimeType))
+			if file.DownloadURL != "" {
+				fmt.Fprintf(&b, " <%s>", file.DownloadURL)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// markdownBlock renders a single block of post content as Markdown
+func markdownBlock(block htmltext.Block) string {
+	switch block.Kind {
+	case htmltext.Heading:
+		// The post title is the only top-level heading
+		level := block.Level + 1
+		if level > 6 {
+			level = 6
+		}
+		return strings.Repeat("#", level) + " " + block.Text
+	case htmltext.ListItem:
+		marker := block.Marker
+		if !strings.HasSuffix(marker, ".") {
+			marker = "-"
+		}
+		indent := strings.Repeat("  ", block.Level-1)
+		return indent + marker + " " + hardBreaks(block.Text, indent+"  ")
+	case htmltext.Quote:
+		return "> " + strings.ReplaceAll(block.Text, "\n", "  \n> ")
+	case htmltext.Preformatted:
+		return "```\n" + block.Text + "\n```"
+	case htmltext.Rule:
+		return "---"
+	}
+	return hardBreaks(block.Text, "")
+}
+
+// hardBreaks keeps line breaks within a paragraph, which Markdown would otherwise join
+func hardBreaks(text, indent string) string {
+	return strings.ReplaceAll(text, "\n", "  \n"+indent)
+}
+
+// renderIndexMarkdown renders a campaign's index page as Markdown
+func renderIndexMarkdown(title string, posts []Post, format Format) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "%d archived post(s), newest first.\n\n", len(posts))
+	for _, post := range posts {
+		fmt.Fprintf(&b, "- %s — [%s](%s)", formatDate(post.Details.PublishedAt),
+			post.Details.Title, (&url.URL{Path: postFileName(post, format)}).EscapedPath())
+		if post.PatreonURL != "" {
+			fmt.Fprintf(&b, " ([original](%s))", post.PatreonURL)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// mediaSummary describes a file's kind, size and mime type, e.g. "audio, 12.3 MB, audio/mpeg"
+func mediaSummary(kind string, size int64, mimeType string) string {
+	parts := []string{kind}
+	if size > 0 {
+		parts = append(parts, download.FormatSize(size))
+	}
+	if mimeType != "" {
+		parts = append(parts, mimeType)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// yamlString quotes a value for YAML front matter
+func yamlString(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
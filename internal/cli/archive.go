@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"patreon-posts/internal/api"
+	"patreon-posts/internal/archive"
+	"patreon-posts/internal/config"
+	"patreon-posts/internal/db"
+	"patreon-posts/internal/models"
+)
+
+// ArchiveOptions selects what the archive command writes and where
+type ArchiveOptions struct {
+	Dir        string         // Root directory for the archive
+	Format     archive.Format // Markdown or self-contained HTML
+	CampaignID string         // Only archive this campaign (default: every saved campaign)
+	Sync       bool           // Page through each campaign on Patreon first, caching posts not yet in the database
+	AfterDate  string         // With Sync, stop paging at posts published before this date (YYYY-MM-DD)
+}
+
+// ArchivePosts writes every cached post of each campaign to disk, one file per post plus an
+// index page per campaign, fetching details that haven't been cached yet
+func ArchivePosts(ctx context.Context, cfg *config.Config, client *api.Client, database *db.Database, opts ArchiveOptions) error {
+	campaigns, err := database.ListCampaigns()
+	if err != nil {
+		return fmt.Errorf("failed to list campaigns: %w", err)
+	}
+	if opts.CampaignID != "" {
+		campaigns = []db.SavedCampaign{{ID: opts.CampaignID}}
+	}
+	if len(campaigns) == 0 {
+		return fmt.Errorf("no saved campaigns to archive")
+	}
+
+	var filterDate time.Time
+	if opts.AfterDate != "" {
+		parsed, err := time.Parse("2006-01-02", opts.AfterDate)
+		if err != nil {
+			return fmt.Errorf("invalid date format '%s', expected YYYY-MM-DD: %w", opts.AfterDate, err)
+		}
+		filterDate = parsed
+	}
+
+	archiver := archive.New(opts.Dir, opts.Format)
+	fmt.Printf("📁 Writing %s archive under: %s\n", opts.Format, opts.Dir)
+	fmt.Printf("📦 Processing %d campaign(s)...\n\n", len(campaigns))
+
+	total := 0
+	for _, campaign := range campaigns {
+		name := campaignName(cfg, database, campaign.ID)
+		display := name
+		if display == "" {
+			display = campaign.ID
+		}
+		fmt.Printf("🎯 Campaign: %s\n", display)
+
+		if opts.Sync {
+			// walkCampaign caches each post and its details as it goes
+			err := walkCampaign(ctx, client, database, campaign.ID, walkOptions{After: filterDate}, nil,
+				func(models.Post, *models.PostDetails) error { return ctx.Err() })
+			if ctx.Err() != nil {
+				fmt.Printf("\n🛑 Interrupted\n")
+				return ctx.Err()
+			}
+			if err != nil {
+				if isFatal(err) {
+					return fmt.Errorf("aborting: %w", err)
+				}
+				fmt.Printf("   ⚠️  Sync stopped early, archiving cached posts: %v\n", err)
+			}
+		}
+
+		count, err := archiveCampaign(ctx, client, database, archiver, campaign.ID, name)
+		total += count
+		if ctx.Err() != nil {
+			fmt.Printf("\n🛑 Interrupted after archiving %d post(s)\n", total)
+			return ctx.Err()
+		}
+		if err != nil {
+			if isFatal(err) {
+				return fmt.Errorf("aborting: %w", err)
+			}
+			fmt.Printf("   ⚠️  Error: %v\n", err)
+			continue
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("✅ Archived %d post(s)\n", total)
+	return nil
+}
+
+// archiveCampaign writes a campaign's cached posts and its index page, returning how many posts were written
+func archiveCampaign(
+	ctx context.Context,
+	client *api.Client,
+	database *db.Database,
+	archiver *archive.Archiver,
+	campaignID, campaignName string,
+) (int, error) {
+	cachedPosts, err := database.GetPostsByCampaign(campaignID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load cached posts: %w", err)
+	}
+	if len(cachedPosts) == 0 {
+		fmt.Printf("   ⏭️  No cached posts; browse the campaign in the TUI or pass --sync\n")
+		return 0, nil
+	}
+
+	var posts []archive.Post
+	for i := range cachedPosts {
+		cached := &cachedPosts[i]
+		if !cached.DetailsCached {
+			if err := fetchMissingDetails(ctx, client, database, cached); err != nil {
+				if ctx.Err() != nil {
+					return len(posts), ctx.Err()
+				}
+				if isFatal(err) || errors.Is(err, api.ErrRateLimited) {
+					return len(posts), err
+				}
+				// Still archive the title and metadata the list gave us
+				fmt.Printf("   ⚠️  No details for post %s, archiving title only: %v\n", cached.ID, err)
+			}
+		}
+
+		post := archive.FromCached(cached)
+		path, err := archiver.WritePost(campaignID, campaignName, post)
+		if err != nil {
+			return len(posts), err
+		}
+		fmt.Printf("   📝 %s\n", path)
+		posts = append(posts, post)
+	}
+
+	indexPath, err := archiver.WriteIndex(campaignID, campaignName, posts)
+	if err != nil {
+		return len(posts), err
+	}
+	fmt.Printf("   📚 Index: %s\n", indexPath)
+	return len(posts), nil
+}
+
+// fetchMissingDetails fetches and caches a post's details, updating cached in place
+func fetchMissingDetails(ctx context.Context, client *api.Client, database *db.Database, cached *db.CachedPost) error {
+	details, err := client.FetchPostDetailsContext(ctx, cached.ID)
+	if err != nil {
+		return err
+	}
+	if err := database.SavePostDetails(details); err != nil {
+		return fmt.Errorf("failed to cache post %s: %w", cached.ID, err)
+	}
+
+	refreshed, err := database.GetPost(cached.ID)
+	if err != nil {
+		return fmt.Errorf("failed to reload post %s: %w", cached.ID, err)
+	}
+	if refreshed == nil {
+		return fmt.Errorf("post %s disappeared from the cache", cached.ID)
+	}
+	*cached = *refreshed
+	return nil
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"patreon-posts/internal/api"
+	"patreon-posts/internal/config"
+	"patreon-posts/internal/db"
+	"patreon-posts/internal/models"
+)
+
+// AddCampaign resolves a campaign ID, creator name or Patreon URL and saves the campaign
+// to both the config file at cfgPath and the database
+func AddCampaign(ctx context.Context, cfgPath string, cfg *config.Config, client *api.Client, database *db.Database, input string) error {
+	campaign, err := client.ResolveCampaignContext(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to resolve campaign %q: %w", input, err)
+	}
+
+	name := campaign.Name
+	if name == "" {
+		name = campaign.Vanity
+	}
+	fmt.Printf("🔎 Found campaign: %s (%s)\n", name, campaign.ID)
+
+	if err := database.SaveCampaign(campaign.ID, name); err != nil {
+		return fmt.Errorf("failed to save campaign: %w", err)
+	}
+
+	// Adding a campaign explicitly undoes an earlier deletion
+	wasDeleted := cfg.IsDeleted(campaign.ID)
+	for _, existing := range cfg.Campaigns {
+		if existing.ID != campaign.ID {
+			continue
+		}
+		if existing.Name != "" && !wasDeleted {
+			fmt.Printf("✅ Campaign is already in %s\n", cfgPath)
+			return nil
+		}
+		cfg.AddCampaign(campaign.ID, name)
+		if err := config.SaveCampaigns(cfgPath, cfg); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Named campaign %s in %s\n", campaign.ID, cfgPath)
+		return nil
+	}
+
+	cfg.AddCampaign(campaign.ID, name)
+	if err := config.SaveCampaigns(cfgPath, cfg); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Added campaign to %s\n", cfgPath)
+	return nil
+}
+
+// SyncMemberships imports the campaigns the current user belongs to into the database,
+// records their tier and pledge status, flags lapsed ones and adds new current
+// memberships to the config file at cfgPath. Campaigns in deleted_campaigns are skipped.
+func SyncMemberships(ctx context.Context, cfgPath string, cfg *config.Config, client *api.Client, database *db.Database) error {
+	fetched, err := client.FetchMembershipsContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch memberships: %w", err)
+	}
+
+	var memberships []models.Membership
+	for _, m := range fetched {
+		if !cfg.IsDeleted(m.Campaign.ID) {
+			memberships = append(memberships, m)
+		}
+	}
+	if skipped := len(fetched) - len(memberships); skipped > 0 {
+		fmt.Printf("  🗑️  Skipping %d campaign(s) you deleted, listed in deleted_campaigns\n", skipped)
+	}
+
+	result, err := database.SyncMemberships(memberships)
+	if err != nil {
+		return err
+	}
+
+	configured := make(map[string]bool, len(cfg.Campaigns))
+	for _, c := range cfg.Campaigns {
+		configured[c.ID] = true
+	}
+
+	known := make(map[string]bool, len(memberships))
+	added := 0
+	for _, m := range memberships {
+		known[m.Campaign.ID] = true
+		fmt.Printf("  %s %s\n", membershipIcon(m), membershipLabel(m))
+		if m.Lapsed() || configured[m.Campaign.ID] {
+			continue
+		}
+		cfg.AddCampaign(m.Campaign.ID, campaignLabel(m.Campaign))
+		configured[m.Campaign.ID] = true
+		added++
+	}
+	for _, id := range result.Lapsed {
+		if known[id] {
+			// Still a membership, already listed above
+			continue
+		}
+		name := id
+		if campaign, err := database.GetCampaign(id); err == nil && campaign != nil && campaign.Name != "" {
+			name = fmt.Sprintf("%s (%s)", campaign.Name, id)
+		}
+		fmt.Printf("  ⚠️  %s - no longer a member\n", name)
+	}
+
+	if added > 0 {
+		if err := config.SaveCampaigns(cfgPath, cfg); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("✅ Synced %d memberships: %d new in the database, %d added to %s, %d lapsed\n",
+		result.Total, len(result.Added), added, cfgPath, len(result.Lapsed))
+	return nil
+}
+
+// ReconcileCampaigns makes the campaigns saved in the database follow the config's campaign list
+func ReconcileCampaigns(cfg *config.Config, database *db.Database) (*db.CampaignReconcile, error) {
+	entries := make([]db.CampaignEntry, len(cfg.Campaigns))
+	for i, campaign := range cfg.Campaigns {
+		entries[i] = db.CampaignEntry{ID: campaign.ID, Name: campaign.Name}
+	}
+	return database.ReconcileCampaigns(entries, cfg.DeletedCampaigns)
+}
+
+// campaignLabel returns the campaign's name, falling back to its vanity name
+func campaignLabel(c models.Campaign) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Vanity
+}
+
+// membershipIcon marks current memberships with a check and lapsed ones with a warning
+func membershipIcon(m models.Membership) string {
+	if m.Lapsed() {
+		return "⚠️ "
+	}
+	return "✓"
+}
+
+// membershipLabel describes a membership as "Name (id) - Tier, pledge 5.00"
+func membershipLabel(m models.Membership) string {
+	label := fmt.Sprintf("%s (%s)", campaignLabel(m.Campaign), m.Campaign.ID)
+	switch {
+	case m.Status == models.PatronStatusDeclined:
+		return label + " - payment declined"
+	case m.Status == models.PatronStatusFormer:
+		return label + " - pledge cancelled"
+	case m.Status == "":
+		return label + " - free member"
+	}
+	pledge := "pledge " + models.FormatCents(m.PledgeCents)
+	if m.Tier != "" {
+		return fmt.Sprintf("%s - %s, %s", label, m.Tier, pledge)
+	}
+	return fmt.Sprintf("%s - %s", label, pledge)
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"patreon-posts/internal/config"
+)
+
+// ConfigFile is the config file in use and how it was chosen
+type ConfigFile struct {
+	Path   string
+	Source string // "default", "env PATREON_POSTS_CONFIG" or "flag --config"
+}
+
+// ShowConfig prints the effective value of every setting and the layer it came from.
+// dbPath is the database that will be opened, which defaults per profile.
+func ShowConfig(cfg *config.Config, file ConfigFile, dbPath string) {
+	fmt.Printf("📄 Config file: %s (%s)\n", file.Path, file.Source)
+	if cfg.Profile != "" {
+		fmt.Printf("👤 Profile:     %s\n", cfg.Profile)
+	}
+	fmt.Printf("🗄️  Database:    %s (%s)\n\n", dbPath, cfg.SourceOf("db_path"))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, setting := range cfg.Settings() {
+		value := setting.Value
+		if setting.Key == "db_path" && value == "" {
+			value = dbPath
+		}
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, value, setting.Source)
+	}
+	w.Flush()
+}
+
+// CheckConfig validates the config file and prints every problem found.
+// It returns false if the file can't be loaded or has errors; warnings don't count.
+func CheckConfig(file ConfigFile) bool {
+	fmt.Printf("📄 Checking %s\n", file.Path)
+	if _, err := os.Stat(file.Path); errors.Is(err, os.ErrNotExist) {
+		fmt.Println("✓ No config file, the defaults are used")
+		return true
+	}
+
+	var problems config.Problems
+	cfg, err := config.Load(file.Path)
+	if errors.Is(err, config.ErrInsecureConfig) {
+		// Report the permissions alongside everything else in the file
+		problems = append(problems, config.Problem{Message: err.Error()})
+		cfg, err = config.LoadInsecure(file.Path)
+	}
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return false
+	}
+	problems = append(problems, cfg.Validate()...)
+
+	if len(problems) == 0 {
+		fmt.Println("✓ No problems found")
+		return true
+	}
+	PrintProblems(os.Stdout, problems)
+
+	errorCount := 0
+	for _, p := range problems {
+		if !p.Warning {
+			errorCount++
+		}
+	}
+	fmt.Printf("\n%d error(s), %d warning(s)\n", errorCount, len(problems)-errorCount)
+	return errorCount == 0
+}
+
+// PrintProblems lists config problems, errors marked ✗ and warnings ⚠
+func PrintProblems(w io.Writer, problems config.Problems) {
+	for _, p := range problems {
+		icon := "✗"
+		if p.Warning {
+			icon = "⚠"
+		}
+		fmt.Fprintf(w, "%s %s\n", icon, p)
+	}
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"patreon-posts/internal/api"
+	"patreon-posts/internal/config"
+	"patreon-posts/internal/db"
+	"patreon-posts/internal/download"
+	"patreon-posts/internal/models"
+)
+
+// DownloadOptions selects which posts the download command saves files from
+type DownloadOptions struct {
+	Dir        string // Root directory for downloaded files
+	CampaignID string // Only download from this campaign (default: all configured campaigns)
+	PostID     string // Only download this post
+	AfterDate  string // Only download posts published after this date (YYYY-MM-DD)
+}
+
+// downloadStats counts file outcomes across a run
+type downloadStats struct {
+	downloaded, skipped, failed int
+	bytes                       int64
+}
+
+// DownloadMedia saves the attachments, images and audio of posts into a per-campaign/per-post
+// directory tree. Files completed by earlier runs are skipped and partial files are resumed.
+func DownloadMedia(ctx context.Context, cfg *config.Config, client *api.Client, database *db.Database, opts DownloadOptions) error {
+	downloader := download.New(client, database, opts.Dir)
+	var stats downloadStats
+
+	fmt.Printf("📁 Saving files under: %s\n", opts.Dir)
+
+	if opts.PostID != "" {
+		err := downloadSinglePost(ctx, cfg, client, database, downloader, opts, &stats)
+		printDownloadStats(stats)
+		return err
+	}
+
+	campaigns := cfg.Campaigns
+	if opts.CampaignID != "" {
+		campaigns = []config.Campaign{{ID: opts.CampaignID, Name: campaignName(cfg, database, opts.CampaignID)}}
+	}
+	if len(campaigns) == 0 {
+		return fmt.Errorf("no campaigns configured in config file")
+	}
+
+	var filterDate time.Time
+	if opts.AfterDate != "" {
+		parsed, err := time.Parse("2006-01-02", opts.AfterDate)
+		if err != nil {
+			return fmt.Errorf("invalid date format '%s', expected YYYY-MM-DD: %w", opts.AfterDate, err)
+		}
+		filterDate = parsed
+		fmt.Printf("📅 Filtering posts after: %s\n", filterDate.Format("2006-01-02"))
+	}
+	fmt.Printf("📦 Processing %d campaign(s)...\n\n", len(campaigns))
+
+	// Details cached before media was tracked need fetching again
+	mediaUnknown := func(p *db.CachedPost) bool { return p.Media == "" }
+
+	for _, campaign := range campaigns {
+		name := campaign.Name
+		if name == "" {
+			name = campaign.ID
+		}
+		fmt.Printf("🎯 Campaign: %s\n", name)
+
+		err := walkCampaign(ctx, client, database, campaign.ID, walkOptions{After: filterDate}, mediaUnknown,
+			func(post models.Post, details *models.PostDetails) error {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				results := downloader.DownloadPost(ctx, campaign.ID, campaign.Name, details)
+				printDownloadResults(details, results, &stats)
+				return ctx.Err()
+			})
+
+		if ctx.Err() != nil {
+			fmt.Printf("\n🛑 Interrupted, partial files will resume on the next run\n")
+			printDownloadStats(stats)
+			return ctx.Err()
+		}
+		if err != nil {
+			if isFatal(err) {
+				printDownloadStats(stats)
+				return fmt.Errorf("aborting: %w", err)
+			}
+			fmt.Printf("   ⚠️  Error: %v\n", err)
+			continue
+		}
+		fmt.Println()
+	}
+
+	printDownloadStats(stats)
+	return nil
+}
+
+// downloadSinglePost saves the files of one post, placing them under its cached campaign if known
+func downloadSinglePost(
+	ctx context.Context,
+	cfg *config.Config,
+	client *api.Client,
+	database *db.Database,
+	downloader *download.Downloader,
+	opts DownloadOptions,
+	stats *downloadStats,
+) error {
+	campaignID := opts.CampaignID
+	if cached, err := database.GetPost(opts.PostID); err == nil && cached != nil && campaignID == "" {
+		campaignID = cached.CampaignID
+	}
+	if campaignID == "" {
+		return fmt.Errorf("post %s is not cached, use --campaign to say which campaign it belongs to", opts.PostID)
+	}
+
+	details, err := client.FetchPostDetailsContext(ctx, opts.PostID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch post %s: %w", opts.PostID, err)
+	}
+	database.SavePostDetails(details)
+
+	results := downloader.DownloadPost(ctx, campaignID, campaignName(cfg, database, campaignID), details)
+	printDownloadResults(details, results, stats)
+	return ctx.Err()
+}
+
+// campaignName looks up a campaign's display name in the config, then the database
+func campaignName(cfg *config.Config, database *db.Database, campaignID string) string {
+	if cfg != nil {
+		for _, campaign := range cfg.Campaigns {
+			if campaign.ID == campaignID && campaign.Name != "" {
+				return campaign.Name
+			}
+		}
+	}
+	if campaign, err := database.GetCampaign(campaignID); err == nil && campaign != nil {
+		return campaign.Name
+	}
+	return ""
+}
+
+// printDownloadResults reports the outcome of each file of a post and adds them to stats
+func printDownloadResults(details *models.PostDetails, results []download.Result, stats *downloadStats) {
+	if len(results) == 0 {
+		return
+	}
+	fmt.Printf("   📝 %s\n", details.Title)
+	for _, result := range results {
+		switch result.Status {
+		case download.StatusDownloaded:
+			stats.downloaded++
+			stats.bytes += result.Bytes
+			fmt.Printf("      💾 %s (%s)\n", result.Path, download.FormatSize(result.Bytes))
+		case download.StatusSkipped:
+			stats.skipped++
+			fmt.Printf("      ⏭️  Already downloaded: %s\n", result.Path)
+		case download.StatusFailed:
+			stats.failed++
+			fmt.Printf("      ⚠️  Failed %s: %v\n", result.File.FileName, result.Err)
+		}
+	}
+}
+
+// printDownloadStats writes a summary of the run to the terminal
+func printDownloadStats(stats downloadStats) {
+	fmt.Printf("\n✅ Downloaded %d file(s) (%s), skipped %d, failed %d\n",
+		stats.downloaded, download.FormatSize(stats.bytes), stats.skipped, stats.failed)
+}
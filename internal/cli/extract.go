@@ -1,44 +1,43 @@
 package cli
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"math/rand"
 	"strings"
 	"time"
 
 	"patreon-posts/internal/api"
 	"patreon-posts/internal/config"
 	"patreon-posts/internal/db"
+	"patreon-posts/internal/links"
+	"patreon-posts/internal/models"
 )
 
-// ExtractYouTubeLinks goes through all campaigns, fetches posts after the given date,
-// extracts YouTube links, copies them to clipboard, and prints them to terminal
-func ExtractYouTubeLinks(cfg *config.Config, database *db.Database, afterDate string) error {
+// ExtractLinks goes through all campaigns, fetches posts after the configured date,
+// extracts links from every enabled provider, and prints them to the terminal grouped
+// by provider. Each campaign's settings in the config override the top-level ones.
+// If ctx is cancelled the links gathered so far are still printed.
+func ExtractLinks(ctx context.Context, cfg *config.Config, client *api.Client, database *db.Database) error {
 	if len(cfg.Campaigns) == 0 {
 		return fmt.Errorf("no campaigns configured in config file")
 	}
 
-	// Parse date filter
-	var filterDate time.Time
-	if afterDate != "" {
-		parsed, err := time.Parse("2006-01-02", afterDate)
-		if err != nil {
-			return fmt.Errorf("invalid date format '%s', expected YYYY-MM-DD: %w", afterDate, err)
+	// Check every date filter before making any requests
+	for _, campaign := range cfg.Campaigns {
+		if _, err := parseDate(cfg.CampaignSettings(campaign.ID).PublishedAfter); err != nil {
+			return err
 		}
-		filterDate = parsed
-		fmt.Printf("📅 Filtering posts after: %s\n", filterDate.Format("2006-01-02"))
+	}
+	if cfg.PublishedAfter != "" {
+		fmt.Printf("📅 Filtering posts after: %s\n", cfg.PublishedAfter)
 	}
 
-	client := api.NewClient(cfg.Cookies)
-	minDelayMs := cfg.GetRequestDelayMinMs()
-	maxDelayMs := cfg.GetRequestDelayMaxMs()
-
-	fmt.Printf("⏱️  Request delays: %dms - %dms\n", minDelayMs, maxDelayMs)
+	fmt.Printf("⏱️  Request delays: %dms - %dms (burst %d)\n",
+		cfg.GetRequestDelayMinMs(), cfg.GetRequestDelayMaxMs(), cfg.GetRequestBurst())
 	fmt.Printf("📦 Processing %d campaign(s)...\n\n", len(cfg.Campaigns))
 
-	var allLinks []string
-	seenLinks := make(map[string]bool)
+	var allLinks []models.ProviderLink
 
 	for _, campaign := range cfg.Campaigns {
 		campaignName := campaign.Name
@@ -47,127 +46,128 @@ func ExtractYouTubeLinks(cfg *config.Config, database *db.Database, afterDate st
 		}
 		fmt.Printf("🎯 Campaign: %s\n", campaignName)
 
-		links, err := extractLinksFromCampaign(client, database, campaign.ID, filterDate, minDelayMs, maxDelayMs)
-		if err != nil {
-			fmt.Printf("   ⚠️  Error: %v\n", err)
-			continue
+		settings := cfg.CampaignSettings(campaign.ID)
+		if len(settings.Overrides) > 0 {
+			fmt.Printf("   ⚙️  Campaign overrides: %s\n", strings.Join(settings.Overrides, ", "))
 		}
+		found, err := extractLinksFromCampaign(ctx, campaignClient(cfg, client, settings), database, campaign.ID, settings)
 
-		// Deduplicate links
-		for _, link := range links {
-			if !seenLinks[link] {
-				seenLinks[link] = true
-				allLinks = append(allLinks, link)
-			}
+		// Deduplicate links, keeping partial results from a failed or interrupted campaign
+		for _, link := range found {
+			allLinks, _ = links.Insert(allLinks, link)
 		}
 
-		fmt.Printf("   ✅ Found %d unique YouTube link(s)\n\n", len(links))
+		if ctx.Err() != nil {
+			fmt.Printf("\n🛑 Interrupted, stopping early\n")
+			printLinks(allLinks)
+			return ctx.Err()
+		}
 
-		// Random delay between campaigns
-		randomDelay(minDelayMs, maxDelayMs)
+		if err != nil {
+			// Every remaining campaign would fail the same way, so abort the run
+			if isFatal(err) {
+				printLinks(allLinks)
+				return fmt.Errorf("aborting: %w", err)
+			}
+			if errors.Is(err, api.ErrForbidden) {
+				fmt.Printf("   🔒 No access to this campaign, skipping: %v\n", err)
+			} else if errors.Is(err, api.ErrRateLimited) {
+				fmt.Printf("   ⏳ Rate limited by Patreon, skipping rest of campaign: %v\n", err)
+			} else {
+				fmt.Printf("   ⚠️  Error: %v\n", err)
+			}
+			continue
+		}
+
+		fmt.Printf("   ✅ Found %d unique link(s)\n\n", countUnique(found))
 	}
 
+	printLinks(allLinks)
+	return nil
+}
+
+// printLinks writes the collected links to the terminal under a heading for each provider
+func printLinks(allLinks []models.ProviderLink) {
 	if len(allLinks) == 0 {
-		fmt.Println("❌ No YouTube links found")
-		return nil
+		fmt.Println("❌ No links found")
+		return
 	}
 
-	// Print links
-	fmt.Printf("\n🎬 YouTube Links (%d total):\n", len(allLinks))
+	fmt.Printf("\n🔗 Links (%d total):\n", len(allLinks))
 	fmt.Println(strings.Repeat("─", 60))
-	for _, link := range allLinks {
-		fmt.Println(link)
+	for i, link := range allLinks {
+		if i == 0 || allLinks[i-1].Provider != link.Provider {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("# %s\n", links.DisplayName(link.Provider))
+		}
+		fmt.Println(link.URL)
 	}
 	fmt.Println(strings.Repeat("─", 60))
+}
 
-	return nil
+// countUnique returns the number of distinct URLs in found
+func countUnique(found []models.ProviderLink) int {
+	seen := make(map[string]bool)
+	for _, link := range found {
+		seen[link.URL] = true
+	}
+	return len(seen)
 }
 
-// extractLinksFromCampaign fetches all posts for a campaign and extracts YouTube links
+// extractLinksFromCampaign fetches all posts for a campaign and extracts the links of the
+// campaign's enabled providers
 func extractLinksFromCampaign(
+	ctx context.Context,
 	client *api.Client,
 	database *db.Database,
 	campaignID string,
-	filterDate time.Time,
-	minDelayMs, maxDelayMs int,
-) ([]string, error) {
-	var allLinks []string
-	cursor := ""
-	pageCount := 0
-	postsProcessed := 0
-
-	for {
-		pageCount++
-		fmt.Printf("   📄 Fetching page %d...\n", pageCount)
-
-		page, err := client.FetchPosts(campaignID, 50, cursor)
-		if err != nil {
-			return allLinks, fmt.Errorf("failed to fetch posts: %w", err)
-		}
-
-		// Random delay after fetching page
-		randomDelay(minDelayMs, maxDelayMs)
-
-		// Process posts
-		for _, post := range page.Posts {
-			// Skip posts before filter date
-			if !filterDate.IsZero() && post.PublishedAt.Before(filterDate) {
-				// Since posts are sorted by date descending, we can stop early
-				fmt.Printf("   ⏭️  Reached posts before filter date, stopping\n")
-				return allLinks, nil
-			}
-
-			postsProcessed++
-
-			// Check if we have cached details
-			cached, err := database.GetPost(post.ID)
-			if err == nil && cached != nil && cached.DetailsCached {
-				// Use cached YouTube links
-				if cached.YouTubeLinks != "" {
-					var links []string
-					if err := json.Unmarshal([]byte(cached.YouTubeLinks), &links); err == nil {
-						allLinks = append(allLinks, links...)
-					}
-				}
-				continue
-			}
+	settings config.CampaignSettings,
+) ([]models.ProviderLink, error) {
+	filterDate, err := parseDate(settings.PublishedAfter)
+	if err != nil {
+		return nil, err
+	}
+	var registry *links.Registry
+	if settings.LinkProviders != nil {
+		registry = links.NewDefaultRegistry(settings.LinkProviders)
+	}
 
-			// Fetch post details
-			details, err := client.FetchPostDetails(post.ID)
-			if err != nil {
-				fmt.Printf("   ⚠️  Failed to fetch post %s: %v\n", post.ID, err)
-				randomDelay(minDelayMs, maxDelayMs)
-				continue
+	var allLinks []models.ProviderLink
+	opts := walkOptions{After: filterDate, Filter: settings.Filter, PageSize: settings.PageSize}
+	err = walkCampaign(ctx, client, database, campaignID, opts, nil,
+		func(post models.Post, details *models.PostDetails) error {
+			if registry != nil {
+				// Cached and fetched details were extracted with the top-level providers
+				registry.Apply(details)
 			}
+			allLinks = append(allLinks, details.ProviderLinks...)
+			return nil
+		})
+	return allLinks, err
+}
 
-			// Cache the details
-			linksJSON, _ := json.Marshal(details.YouTubeLinks)
-			database.SavePostDetails(post.ID, details.Description, string(linksJSON))
-
-			allLinks = append(allLinks, details.YouTubeLinks...)
-
-			// Random delay after each post detail fetch
-			randomDelay(minDelayMs, maxDelayMs)
-		}
-
-		fmt.Printf("   📊 Processed %d posts so far\n", postsProcessed)
-
-		// Check if there are more pages
-		if !page.HasMore || page.NextCursor == "" {
-			break
-		}
-		cursor = page.NextCursor
+// campaignClient returns client with the campaign's request delays, if it overrides them
+func campaignClient(cfg *config.Config, client *api.Client, settings config.CampaignSettings) *api.Client {
+	if !settings.Overridden("request_delays") {
+		return client
 	}
-
-	return allLinks, nil
+	return client.WithRateLimit(api.RateLimit{
+		MinDelay: time.Duration(settings.RequestDelayMinMs) * time.Millisecond,
+		MaxDelay: time.Duration(settings.RequestDelayMaxMs) * time.Millisecond,
+		Burst:    cfg.GetRequestBurst(),
+	})
 }
 
-// randomDelay sleeps for a random duration between min and max milliseconds
-func randomDelay(minMs, maxMs int) {
-	if maxMs <= minMs {
-		time.Sleep(time.Duration(minMs) * time.Millisecond)
-		return
+// parseDate parses a YYYY-MM-DD date filter, returning the zero time for an empty one
+func parseDate(date string) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date format '%s', expected YYYY-MM-DD: %w", date, err)
 	}
-	delay := minMs + rand.Intn(maxMs-minMs)
-	time.Sleep(time.Duration(delay) * time.Millisecond)
+	return parsed, nil
 }
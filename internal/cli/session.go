@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"patreon-posts/internal/api"
+	"patreon-posts/internal/models"
+)
+
+// CheckSession verifies the session cookies before a command or the TUI starts, printing
+// who is logged in or a warning. It returns false only if the session is anonymous or
+// expired; other failures are reported but don't count against the session.
+func CheckSession(ctx context.Context, client *api.Client) bool {
+	user, err := client.FetchCurrentUserContext(ctx)
+	if sessionRejected(err) {
+		fmt.Println("⚠️  Your Patreon session is anonymous or has expired.")
+		fmt.Println("   Patron-only posts will show as locked. Update your cookies, or run 'whoami' to check them.")
+		fmt.Println()
+		return false
+	}
+	if err != nil {
+		fmt.Printf("⚠️  Could not check your Patreon session: %v\n\n", err)
+		return true
+	}
+
+	fmt.Printf("👤 Logged in as %s, %d active memberships\n\n", accountLabel(user), len(user.ActiveMemberships()))
+	return true
+}
+
+// WhoAmI prints the account the session cookies belong to and its memberships
+func WhoAmI(ctx context.Context, client *api.Client) error {
+	user, err := client.FetchCurrentUserContext(ctx)
+	if sessionRejected(err) {
+		return fmt.Errorf("session is anonymous or expired, update your cookies: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch current user: %w", err)
+	}
+
+	fmt.Printf("👤 %s\n", accountLabel(user))
+	fmt.Printf("   User ID: %s\n", user.ID)
+	if user.Vanity != "" {
+		fmt.Printf("   Profile: https://www.patreon.com/%s\n", user.Vanity)
+	}
+
+	active := user.ActiveMemberships()
+	fmt.Printf("\n💳 Active memberships (%d):\n", len(active))
+	for _, m := range active {
+		fmt.Printf("  ✓ %s\n", membershipLabel(m))
+	}
+	if len(active) == 0 {
+		fmt.Println("  (none)")
+	}
+
+	var others []models.Membership
+	for _, m := range user.Memberships {
+		if m.Status != models.PatronStatusActive {
+			others = append(others, m)
+		}
+	}
+	if len(others) > 0 {
+		fmt.Printf("\n📋 Free and lapsed memberships (%d):\n", len(others))
+		for _, m := range others {
+			fmt.Printf("  %s %s\n", membershipIcon(m), membershipLabel(m))
+		}
+	}
+	return nil
+}
+
+// sessionRejected reports whether err means Patreon didn't accept the session cookies
+func sessionRejected(err error) bool {
+	// A 403 is more often Cloudflare than the session, so only a 401 counts
+	return errors.Is(err, api.ErrUnauthorized)
+}
+
+// accountLabel describes the user as "Full Name (@example.com)", without the full email address
+func accountLabel(user *models.User) string {
+	name := user.FullName
+	if name == "" {
+		name = user.Vanity
+	}
+	if name == "" {
+		name = "user " + user.ID
+	}
+	if domain := user.EmailDomain(); domain != "" {
+		return fmt.Sprintf("%s (@%s)", name, domain)
+	}
+	return name
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"patreon-posts/internal/api"
+	"patreon-posts/internal/config"
+	"patreon-posts/internal/db"
+	"patreon-posts/internal/models"
+)
+
+// walkOptions selects the posts walkCampaign visits
+type walkOptions struct {
+	After    time.Time         // Stop at posts published before this, if set
+	Filter   config.PostFilter // Skip posts that don't match, without fetching their details
+	PageSize int               // Posts per page (default: 50)
+}
+
+// postVisitor is called with each post and its details, fetched or from the cache
+type postVisitor func(post models.Post, details *models.PostDetails) error
+
+// walkCampaign pages through a campaign's posts newest first, stopping at opts.After, and passes
+// the details of each post matching opts.Filter to visit. Cached details are used unless stale reports they lack something
+// the caller needs. A post that can't be fetched is skipped unless the error affects every post.
+func walkCampaign(
+	ctx context.Context,
+	client *api.Client,
+	database *db.Database,
+	campaignID string,
+	opts walkOptions,
+	stale func(*db.CachedPost) bool,
+	visit postVisitor,
+) error {
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+	cursor := ""
+	pageCount := 0
+	postsProcessed := 0
+
+	for {
+		pageCount++
+		fmt.Printf("   📄 Fetching page %d...\n", pageCount)
+
+		page, err := client.FetchPostsContext(ctx, campaignID, pageSize, cursor)
+		if err != nil {
+			return fmt.Errorf("failed to fetch posts: %w", err)
+		}
+
+		// Process posts
+		for _, post := range page.Posts {
+			// Skip posts before filter date
+			if !opts.After.IsZero() && post.PublishedAt.Before(opts.After) {
+				// Since posts are sorted by date descending, we can stop early
+				fmt.Printf("   ⏭️  Reached posts before filter date, stopping\n")
+				return nil
+			}
+
+			if !opts.Filter.Match(post) {
+				continue
+			}
+			postsProcessed++
+
+			// Check if we have cached details
+			cached, err := database.GetPost(post.ID)
+			if err == nil && cached != nil && cached.DetailsCached && (stale == nil || !stale(cached)) {
+				if err := visit(post, cached.Details()); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Fetch post details
+			details, err := client.FetchPostDetailsContext(ctx, post.ID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				// Further requests would only be rejected too, so stop this campaign
+				if isFatal(err) || errors.Is(err, api.ErrRateLimited) {
+					return fmt.Errorf("failed to fetch post %s: %w", post.ID, err)
+				}
+				if errors.Is(err, api.ErrNotFound) {
+					fmt.Printf("   🔍 Post %s not found, skipping\n", post.ID)
+				} else if errors.Is(err, api.ErrForbidden) {
+					fmt.Printf("   🔒 No access to post %s, skipping\n", post.ID)
+				} else {
+					fmt.Printf("   ⚠️  Failed to fetch post %s: %v\n", post.ID, err)
+				}
+				continue
+			}
+
+			// Cache the post and its details
+			if cached == nil {
+				database.SavePost(&db.CachedPost{
+					ID:                 post.ID,
+					CampaignID:         campaignID,
+					Type:               post.Type,
+					PostType:           post.PostType,
+					Title:              post.Title,
+					PatreonURL:         post.PatreonURL,
+					CurrentUserCanView: post.CurrentUserCanView,
+					PublishedAt:        post.PublishedAt,
+				})
+			}
+			database.SavePostDetails(details)
+
+			if err := visit(post, details); err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("   📊 Processed %d posts so far\n", postsProcessed)
+
+		// Check if there are more pages
+		if !page.HasMore || page.NextCursor == "" {
+			return nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// isFatal reports whether an API error will affect every campaign, such as an expired session
+func isFatal(err error) bool {
+	return errors.Is(err, api.ErrUnauthorized) || errors.Is(err, api.ErrDecode)
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"regexp"
+	"strings"
+
+	"patreon-posts/internal/models"
+)
+
+// AddCampaign adds a campaign to the list, or renames it if it's already listed, and
+// removes it from deleted_campaigns. An empty name keeps the current one.
+func (c *Config) AddCampaign(id, name string) {
+	c.DeletedCampaigns = removeString(c.DeletedCampaigns, id)
+	for i, campaign := range c.Campaigns {
+		if campaign.ID == id {
+			if name != "" {
+				c.Campaigns[i].Name = name
+			}
+			return
+		}
+	}
+	c.Campaigns = append(c.Campaigns, Campaign{ID: id, Name: name})
+}
+
+// RemoveCampaign removes a campaign from the list and records it in deleted_campaigns,
+// so membership imports and other copies of the list don't bring it back
+func (c *Config) RemoveCampaign(id string) {
+	var kept []Campaign
+	for _, campaign := range c.Campaigns {
+		if campaign.ID != id {
+			kept = append(kept, campaign)
+		}
+	}
+	c.Campaigns = kept
+	if !contains(c.DeletedCampaigns, id) {
+		c.DeletedCampaigns = append(c.DeletedCampaigns, id)
+	}
+}
+
+// IsDeleted reports whether a campaign is listed in deleted_campaigns and not in campaigns,
+// which wins when a campaign is in both
+func (c *Config) IsDeleted(id string) bool {
+	if !contains(c.DeletedCampaigns, id) {
+		return false
+	}
+	for _, campaign := range c.Campaigns {
+		if campaign.ID == id {
+			return false
+		}
+	}
+	return true
+}
+
+// removeString returns values without any occurrence of value
+func removeString(values []string, value string) []string {
+	var kept []string
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// CampaignSettings are the settings a campaign's posts are fetched with: the campaign's
+// overrides merged over the top-level config
+type CampaignSettings struct {
+	PublishedAfter    string          // Date filter (YYYY-MM-DD), or "" for none
+	Filter            PostFilter      // Title and post type filter
+	LinkProviders     map[string]bool // Enabled link providers, nil unless the campaign changes them
+	PageSize          int             // Posts per page, or 0 for the caller's default
+	RequestDelayMinMs int
+	RequestDelayMaxMs int
+	Overrides         []string // Keys the campaign overrides, in config file order
+}
+
+// Overridden reports whether the campaign overrides the top-level value of key
+func (s CampaignSettings) Overridden(key string) bool {
+	for _, k := range s.Overrides {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// CampaignSettings returns the settings for the campaign with the given ID. Campaigns
+// missing from the config use the top-level settings. A date filter set by a flag or
+// environment variable applies to every campaign, since it was asked for explicitly.
+func (c *Config) CampaignSettings(id string) CampaignSettings {
+	s := CampaignSettings{
+		PublishedAfter:    c.PublishedAfter,
+		RequestDelayMinMs: c.GetRequestDelayMinMs(),
+		RequestDelayMaxMs: c.GetRequestDelayMaxMs(),
+	}
+
+	var campaign Campaign
+	for _, candidate := range c.Campaigns {
+		if candidate.ID == id {
+			campaign = candidate
+			break
+		}
+	}
+
+	source := c.SourceOf("published_after")
+	explicit := strings.HasPrefix(source, "flag ") || strings.HasPrefix(source, "env ")
+	if campaign.PublishedAfter != "" && !explicit {
+		s.PublishedAfter = campaign.PublishedAfter
+		s.Overrides = append(s.Overrides, "published_after")
+	}
+	if len(campaign.IncludeTitles) > 0 {
+		s.Filter.include = compilePatterns(campaign.IncludeTitles)
+		s.Overrides = append(s.Overrides, "include_titles")
+	}
+	if len(campaign.ExcludeTitles) > 0 {
+		s.Filter.exclude = compilePatterns(campaign.ExcludeTitles)
+		s.Overrides = append(s.Overrides, "exclude_titles")
+	}
+	if len(campaign.PostTypes) > 0 {
+		s.Filter.postTypes = campaign.PostTypes
+		s.Overrides = append(s.Overrides, "post_types")
+	}
+	if len(campaign.LinkProviders) > 0 {
+		s.LinkProviders = make(map[string]bool)
+		for provider, on := range c.LinkProviders {
+			s.LinkProviders[provider] = on
+		}
+		for provider, on := range campaign.LinkProviders {
+			s.LinkProviders[provider] = on
+		}
+		s.Overrides = append(s.Overrides, "link_providers")
+	}
+	if campaign.PageSize > 0 {
+		s.PageSize = campaign.PageSize
+		s.Overrides = append(s.Overrides, "page_size")
+	}
+	if campaign.RequestDelayMinMs > 0 || campaign.RequestDelayMaxMs > 0 {
+		// Resolve the pair the same way as the top-level delays, with the campaign's values filled in
+		delays := Config{RequestDelayMinMs: c.RequestDelayMinMs, RequestDelayMaxMs: c.RequestDelayMaxMs}
+		if campaign.RequestDelayMinMs > 0 {
+			delays.RequestDelayMinMs = campaign.RequestDelayMinMs
+		}
+		if campaign.RequestDelayMaxMs > 0 {
+			delays.RequestDelayMaxMs = campaign.RequestDelayMaxMs
+		}
+		s.RequestDelayMinMs = delays.GetRequestDelayMinMs()
+		s.RequestDelayMaxMs = delays.GetRequestDelayMaxMs()
+		s.Overrides = append(s.Overrides, "request_delays")
+	}
+	return s
+}
+
+// PostFilter selects posts by title and post type. The zero value matches every post.
+type PostFilter struct {
+	include   []*regexp.Regexp
+	exclude   []*regexp.Regexp
+	postTypes []string
+}
+
+// Active reports whether the filter can reject any post
+func (f PostFilter) Active() bool {
+	return len(f.include) > 0 || len(f.exclude) > 0 || len(f.postTypes) > 0
+}
+
+// Match reports whether a post passes the filter: its type is listed, if types are
+// given, its title matches an include pattern, if any, and no exclude pattern
+func (f PostFilter) Match(post models.Post) bool {
+	if len(f.postTypes) > 0 && !contains(f.postTypes, post.PostType) {
+		return false
+	}
+	if len(f.include) > 0 && !matchesAny(f.include, post.Title) {
+		return false
+	}
+	return !matchesAny(f.exclude, post.Title)
+}
+
+// compilePatterns compiles title patterns case-insensitively. Invalid patterns are
+// skipped; Validate reports them before the config is used.
+func compilePatterns(patterns []string) []*regexp.Regexp {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		if re, err := regexp.Compile("(?i)" + pattern); err == nil {
+			compiled = append(compiled, re)
+		}
+	}
+	return compiled
+}
+
+// matchesAny reports whether s matches one of the patterns
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
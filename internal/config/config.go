@@ -1,38 +1,78 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
 )
 
-// Campaign represents a saved campaign
+// Campaign represents a saved campaign. The optional fields override the top-level
+// settings while this campaign's posts are fetched, see CampaignSettings.
 type Campaign struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID                string          `json:"id"`
+	Name              string          `json:"name"`
+	PublishedAfter    string          `json:"published_after,omitempty"`      // Overrides published_after (YYYY-MM-DD)
+	IncludeTitles     []string        `json:"include_titles,omitempty"`       // Only posts whose title matches one of these regular expressions
+	ExcludeTitles     []string        `json:"exclude_titles,omitempty"`       // Skip posts whose title matches any of these regular expressions
+	PostTypes         []string        `json:"post_types,omitempty"`           // Only posts of these types, e.g. ["video_embed", "text_only"]
+	LinkProviders     map[string]bool `json:"link_providers,omitempty"`       // Merged over the top-level link_providers
+	PageSize          int             `json:"page_size,omitempty"`            // Posts fetched per page (default: 20 in the TUI, 50 elsewhere)
+	RequestDelayMinMs int             `json:"request_delay_min_ms,omitempty"` // Overrides request_delay_min_ms
+	RequestDelayMaxMs int             `json:"request_delay_max_ms,omitempty"` // Overrides request_delay_max_ms
 }
 
 // Config holds the application configuration
 type Config struct {
-	Cookies           string     `json:"cookies"`
-	Campaigns         []Campaign `json:"campaigns,omitempty"`
-	PublishedAfter    string     `json:"published_after,omitempty"`      // Filter posts to those published after this date (YYYY-MM-DD)
-	RequestDelayMinMs int        `json:"request_delay_min_ms,omitempty"` // Minimum delay between requests in ms (default: 1000, min: 1000)
-	RequestDelayMaxMs int        `json:"request_delay_max_ms,omitempty"` // Maximum delay between requests in ms (default: 3000)
-}
+	Cookies           string             `json:"cookies"`
+	CookiesEnv        string             `json:"cookies_env,omitempty"`     // Read cookies from this environment variable
+	CookiesFile       string             `json:"cookies_file,omitempty"`    // Read cookies from this file, which must not be readable by other users
+	CookiesCommand    string             `json:"cookies_command,omitempty"` // Read cookies from the first line printed by this command, e.g. "pass show patreon"
+	CookieSource      string             `json:"cookie_source,omitempty"`   // Read cookies from "firefox", "chrome", "chromium" or a cookies.txt path instead
+	Campaigns         []Campaign         `json:"campaigns,omitempty"`
+	DeletedCampaigns  []string           `json:"deleted_campaigns,omitempty"`    // IDs of campaigns deleted in the TUI, which imports don't bring back
+	PublishedAfter    string             `json:"published_after,omitempty"`      // Filter posts to those published after this date (YYYY-MM-DD)
+	RequestDelayMinMs int                `json:"request_delay_min_ms,omitempty"` // Minimum delay between requests in ms (default: 1000, min: 1000)
+	RequestDelayMaxMs int                `json:"request_delay_max_ms,omitempty"` // Maximum delay between requests in ms (default: 3000)
+	RequestBurst      int                `json:"request_burst,omitempty"`        // Requests allowed back-to-back before delays apply (default: 1)
+	APIBaseURL        string             `json:"api_base_url,omitempty"`         // Patreon API base URL (default: https://www.patreon.com/api)
+	UserAgent         string             `json:"user_agent,omitempty"`           // User-Agent header sent with API requests
+	ProxyURL          string             `json:"proxy_url,omitempty"`            // HTTP(S) proxy for API requests (default: environment proxy settings)
+	RequestTimeoutMs  int                `json:"request_timeout_ms,omitempty"`   // Timeout per API request in ms (default: 30000, -1 disables)
+	MaxRetries        int                `json:"max_retries,omitempty"`          // Retries for rate-limited or failed requests (default: 3, -1 disables)
+	RetryBaseDelayMs  int                `json:"retry_base_delay_ms,omitempty"`  // Backoff before the first retry in ms, doubled per retry (default: 2000)
+	RetryMaxDelayMs   int                `json:"retry_max_delay_ms,omitempty"`   // Maximum backoff or Retry-After wait in ms (default: 60000)
+	LinkProviders     map[string]bool    `json:"link_providers,omitempty"`       // Enable/disable link providers by key, e.g. {"mega": false} (default: all enabled)
+	DownloadDir       string             `json:"download_dir,omitempty"`         // Directory post attachments are downloaded into (default: ./patreon-downloads)
+	ArchiveDir        string             `json:"archive_dir,omitempty"`          // Directory the post archive is written to (default: ./patreon-archive)
+	DBPath            string             `json:"db_path,omitempty"`              // SQLite database (default: patreon-posts.db in the XDG data directory)
+	Profiles          map[string]Profile `json:"profiles,omitempty"`             // Named accounts selected with --profile
+	DefaultProfile    string             `json:"default_profile,omitempty"`      // Profile used when --profile isn't given
 
-// DefaultConfigPath returns the default config file path
-func DefaultConfigPath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
-	}
-	return filepath.Join(home, ".patreon-posts.json"), nil
+	Profile string            `json:"-"` // Name of the profile applied by WithProfile, if any
+	sources map[string]string // Layer each key was set by, see SourceOf
+	unknown []Problem         // Keys in the file that no field matches, reported by Validate
 }
 
-// Load reads configuration from file
+// ErrInsecureConfig is returned by Load when a config file holding cookies can be read by other users.
+// The database, which stores rotated cookies, is checked by db.Open.
+var ErrInsecureConfig = errors.New("config file contains cookies and is readable by other users")
+
+// Load reads configuration from file, refusing a world-readable file that contains cookies
 func Load(path string) (*Config, error) {
+	return load(path, false)
+}
+
+// LoadInsecure is like Load but accepts a world-readable file that contains cookies
+func LoadInsecure(path string) (*Config, error) {
+	return load(path, true)
+}
+
+func load(path string, allowInsecure bool) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -43,12 +83,44 @@ func Load(path string) (*Config, error) {
 
 	var cfg Config
 	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, parseError(data, err))
+	}
+	cfg.unknown = unknownFields(data, reflect.TypeOf(cfg), "")
+
+	// Remember which keys the file sets, for SourceOf
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err == nil {
+		for key := range keys {
+			cfg.setSource(key, "file "+path)
+		}
+	}
+
+	if cfg.hasInlineCookies() && !allowInsecure && runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		if info.Mode().Perm()&0o004 != 0 {
+			return nil, fmt.Errorf("%w: run chmod 600 %s, or move the cookies to cookies_env, cookies_file or cookies_command", ErrInsecureConfig, path)
+		}
 	}
 
 	return &cfg, nil
 }
 
+// hasInlineCookies reports whether the file itself holds cookies, at the top level or in a profile
+func (c *Config) hasInlineCookies() bool {
+	if c.Cookies != "" {
+		return true
+	}
+	for _, p := range c.Profiles {
+		if p.Cookies != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // GetRequestDelayMinMs returns the minimum request delay in ms (defaults to 1000, enforces minimum of 1000)
 func (c *Config) GetRequestDelayMinMs() int {
 	if c.RequestDelayMinMs < 1000 {
@@ -70,14 +142,111 @@ func (c *Config) GetRequestDelayMaxMs() int {
 	return c.RequestDelayMaxMs
 }
 
-// Save writes configuration to file
+// GetRequestBurst returns the number of requests allowed without delay (defaults to 1)
+func (c *Config) GetRequestBurst() int {
+	if c.RequestBurst < 1 {
+		return 1
+	}
+	return c.RequestBurst
+}
+
+// GetRequestTimeoutMs returns the per-request timeout in ms (defaults to 30000, negative
+// disables it, giving 0)
+func (c *Config) GetRequestTimeoutMs() int {
+	if c.RequestTimeoutMs < 0 {
+		return 0
+	}
+	if c.RequestTimeoutMs == 0 {
+		return 30000
+	}
+	return c.RequestTimeoutMs
+}
+
+// GetMaxRetries returns the number of retries for transient failures (defaults to 3, negative disables)
+func (c *Config) GetMaxRetries() int {
+	if c.MaxRetries < 0 {
+		return 0
+	}
+	if c.MaxRetries == 0 {
+		return 3
+	}
+	return c.MaxRetries
+}
+
+// GetRetryBaseDelayMs returns the initial retry backoff in ms (defaults to 2000)
+func (c *Config) GetRetryBaseDelayMs() int {
+	if c.RetryBaseDelayMs <= 0 {
+		return 2000
+	}
+	return c.RetryBaseDelayMs
+}
+
+// GetRetryMaxDelayMs returns the maximum retry wait in ms (defaults to 60000, at least the base delay)
+func (c *Config) GetRetryMaxDelayMs() int {
+	if c.RetryMaxDelayMs <= 0 {
+		return 60000
+	}
+	if baseMs := c.GetRetryBaseDelayMs(); c.RetryMaxDelayMs < baseMs {
+		return baseMs
+	}
+	return c.RetryMaxDelayMs
+}
+
+// GetDownloadDir returns the directory downloads are saved under (defaults to ./patreon-downloads)
+func (c *Config) GetDownloadDir() string {
+	if c.DownloadDir == "" {
+		return "patreon-downloads"
+	}
+	return c.DownloadDir
+}
+
+// GetArchiveDir returns the directory the post archive is written to (defaults to ./patreon-archive)
+func (c *Config) GetArchiveDir() string {
+	if c.ArchiveDir == "" {
+		return "patreon-archive"
+	}
+	return c.ArchiveDir
+}
+
+// Save writes configuration to file. An existing file is patched so its formatting, key
+// order and any keys this version doesn't know are kept. The file is replaced atomically
+// and is only readable by its owner, since it may contain cookies.
 func Save(path string, cfg *Config) error {
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	// Patch the existing file rather than replacing it, so hand-made formatting survives
+	if old, err := os.ReadFile(path); err == nil && len(bytes.TrimSpace(old)) > 0 {
+		if patched, err := patchJSON(old, data, reflect.TypeOf(*cfg)); err == nil {
+			data = patched
+		}
+	} else {
+		data = append(data, '\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	// os.CreateTemp creates the file with mode 0600
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	// Recorded before the file appears, so a Watcher never sees it unrecorded
+	recordWrite(path, data)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		recordWrite(path, nil)
 		return fmt.Errorf("failed to write config: %w", err)
 	}
 
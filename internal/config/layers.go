@@ -0,0 +1,231 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix starts the environment variables that override config keys, e.g. PATREON_POSTS_PROXY_URL
+const EnvPrefix = "PATREON_POSTS_"
+
+// SourceDefault is the source of settings no layer has set
+const SourceDefault = "default"
+
+// cookieKeys are the settings that each supply the session cookies. Setting one from
+// a higher layer clears the others, so the most specific layer decides the cookies.
+var cookieKeys = []string{"cookies", "cookies_env", "cookies_file", "cookies_command", "cookie_source"}
+
+// Setting is the effective value of a config key and the layer it came from
+type Setting struct {
+	Key    string
+	Value  string
+	Source string // "default", "file <path>", "profile <name>", "env <VAR>" or "flag --<name>"
+}
+
+// SourceOf returns the layer the key's value came from
+func (c *Config) SourceOf(key string) string {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// setSource records the layer a key's value came from
+func (c *Config) setSource(key, source string) {
+	if c.sources == nil {
+		c.sources = make(map[string]string)
+	}
+	c.sources[key] = source
+}
+
+// Set assigns a string or integer key from a layer above the config file, such as an
+// environment variable or flag. source describes the layer for SourceOf.
+func (c *Config) Set(key, value, source string) error {
+	field, ok := c.field(key)
+	if !ok {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+
+	if err := checkValue(key, value); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a whole number", source, value)
+		}
+		field.SetInt(int64(n))
+	default:
+		return fmt.Errorf("config key %q can only be set in the config file", key)
+	}
+
+	if isCookieKey(key) {
+		for _, other := range cookieKeys {
+			if other != key {
+				f, _ := c.field(other)
+				f.SetString("")
+				c.setSource(other, source)
+			}
+		}
+	}
+	c.setSource(key, source)
+	return nil
+}
+
+// checkValue checks a value set above the config file the way Validate checks the file's
+func checkValue(key, value string) error {
+	v := &validator{}
+	switch key {
+	case "published_after":
+		v.date(key, value)
+	case "api_base_url", "proxy_url":
+		v.url(key, value)
+	case "cookie_source":
+		v.cookieOptions("", "", "", "", "", value)
+	}
+	if len(v.problems) > 0 && !v.problems[0].Warning {
+		return errors.New(v.problems[0].Message)
+	}
+	return nil
+}
+
+// ApplyEnv sets every string and integer key from its PATREON_POSTS_<KEY> environment
+// variable, if present. lookup is usually os.LookupEnv.
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	for _, key := range c.scalarKeys() {
+		if key == "default_profile" {
+			// The profile has already been applied; PATREON_POSTS_PROFILE selects one
+			continue
+		}
+		name := EnvPrefix + strings.ToUpper(key)
+		if value, ok := lookup(name); ok && value != "" {
+			if err := c.Set(key, value, "env "+name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Settings lists the effective value and source of every key, in config file order.
+// Cookie values are masked.
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+	t := reflect.TypeOf(*c)
+	for i := 0; i < t.NumField(); i++ {
+		key := jsonKey(t.Field(i))
+		if key == "" {
+			continue
+		}
+		settings = append(settings, Setting{Key: key, Value: c.displayValue(key), Source: c.SourceOf(key)})
+	}
+	return settings
+}
+
+// displayValue formats the effective value of key for Settings
+func (c *Config) displayValue(key string) string {
+	switch key {
+	case "cookies":
+		if c.Cookies == "" {
+			return ""
+		}
+		return fmt.Sprintf("(set, %d characters)", len(c.Cookies))
+	case "campaigns":
+		names := make([]string, len(c.Campaigns))
+		for i, campaign := range c.Campaigns {
+			names[i] = campaign.ID
+			if campaign.Name != "" {
+				names[i] = fmt.Sprintf("%s (%s)", campaign.Name, campaign.ID)
+			}
+		}
+		return strings.Join(names, ", ")
+	case "deleted_campaigns":
+		return strings.Join(c.DeletedCampaigns, ", ")
+	case "profiles":
+		return strings.Join(c.ProfileNames(), ", ")
+	case "link_providers":
+		var parts []string
+		for provider, on := range c.LinkProviders {
+			parts = append(parts, fmt.Sprintf("%s=%t", provider, on))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, ", ")
+	case "request_delay_min_ms":
+		return strconv.Itoa(c.GetRequestDelayMinMs())
+	case "request_delay_max_ms":
+		return strconv.Itoa(c.GetRequestDelayMaxMs())
+	case "request_burst":
+		return strconv.Itoa(c.GetRequestBurst())
+	case "request_timeout_ms":
+		return strconv.Itoa(c.GetRequestTimeoutMs())
+	case "max_retries":
+		return strconv.Itoa(c.GetMaxRetries())
+	case "retry_base_delay_ms":
+		return strconv.Itoa(c.GetRetryBaseDelayMs())
+	case "retry_max_delay_ms":
+		return strconv.Itoa(c.GetRetryMaxDelayMs())
+	case "download_dir":
+		return c.GetDownloadDir()
+	case "archive_dir":
+		return c.GetArchiveDir()
+	}
+
+	field, ok := c.field(key)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(field.Interface())
+}
+
+// field returns the settable struct field for a JSON key
+func (c *Config) field(key string) (reflect.Value, bool) {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if jsonKey(t.Field(i)) == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// scalarKeys lists the keys of string and integer fields, which layers above the file can set
+func (c *Config) scalarKeys() []string {
+	var keys []string
+	t := reflect.TypeOf(*c)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := jsonKey(f)
+		if key != "" && (f.Type.Kind() == reflect.String || f.Type.Kind() == reflect.Int) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// jsonKey returns the JSON name of a struct field, or "" if it isn't serialized
+func jsonKey(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" || !f.IsExported() {
+		return ""
+	}
+	return name
+}
+
+// isCookieKey reports whether key supplies the session cookies
+func isCookieKey(key string) bool {
+	for _, k := range cookieKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
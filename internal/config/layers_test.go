@@ -0,0 +1,68 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		key, value, source string
+		wantErr            string
+	}{
+		{key: "published_after", value: "2024-01-31", source: "flag --after"},
+		{key: "published_after", value: "2024-13-01", source: "flag --after", wantErr: `flag --after: "2024-13-01" is not a date in YYYY-MM-DD form`},
+		{key: "proxy_url", value: "http://localhost:8080", source: "env PATREON_POSTS_PROXY_URL"},
+		{key: "proxy_url", value: "localhost:8080", source: "env PATREON_POSTS_PROXY_URL", wantErr: "env PATREON_POSTS_PROXY_URL: "},
+		{key: "api_base_url", value: "ftp://example.com", source: "flag --api-url", wantErr: `flag --api-url: "ftp://example.com" must be an http:// or https:// URL`},
+		{key: "cookie_source", value: "netscape-navigator", source: "flag --cookies-from", wantErr: "flag --cookies-from: "},
+		{key: "request_timeout_ms", value: "soon", source: "env PATREON_POSTS_REQUEST_TIMEOUT_MS", wantErr: `env PATREON_POSTS_REQUEST_TIMEOUT_MS: "soon" is not a whole number`},
+		{key: "campaigns", value: "1", source: "env PATREON_POSTS_CAMPAIGNS", wantErr: "can only be set in the config file"},
+		{key: "nope", value: "1", source: "flag --nope", wantErr: `unknown config key "nope"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			cfg := &Config{}
+			err := cfg.Set(tt.key, tt.value, tt.source)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Set() = %v", err)
+				}
+				if got := cfg.SourceOf(tt.key); got != tt.source {
+					t.Errorf("SourceOf() = %q, want %q", got, tt.source)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Set() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyEnvRejectsBadValues(t *testing.T) {
+	env := map[string]string{
+		EnvPrefix + "PROXY_URL":       "http://proxy:3128",
+		EnvPrefix + "PUBLISHED_AFTER": "yesterday",
+	}
+	cfg := &Config{}
+	err := cfg.ApplyEnv(func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+	want := `env PATREON_POSTS_PUBLISHED_AFTER: "yesterday" is not a date in YYYY-MM-DD form`
+	if err == nil || err.Error() != want {
+		t.Errorf("ApplyEnv() = %v, want %q", err, want)
+	}
+}
+
+func TestSetCookieKeyReplacesOthers(t *testing.T) {
+	cfg := &Config{Cookies: "session_id=abc", CookiesFile: "/tmp/cookies"}
+	if err := cfg.Set("cookies_command", "pass show patreon", "flag --cookies-command"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Cookies != "" || cfg.CookiesFile != "" || cfg.CookiesCommand != "pass show patreon" {
+		t.Errorf("cookie options = %q, %q, %q, want only the command", cfg.Cookies, cfg.CookiesFile, cfg.CookiesCommand)
+	}
+}
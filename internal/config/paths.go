@@ -0,0 +1,175 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// AppName is the directory name used under the XDG config and data directories
+const AppName = "patreon-posts"
+
+// ConfigDir returns $XDG_CONFIG_HOME/patreon-posts, defaulting to ~/.config/patreon-posts
+func ConfigDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// DataDir returns $XDG_DATA_HOME/patreon-posts, defaulting to ~/.local/share/patreon-posts
+func DataDir() (string, error) {
+	return xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// xdgDir returns the app directory under the base directory named by env, or under
+// fallback in the home directory when env is unset or not absolute, as the spec requires
+func xdgDir(env, fallback string) (string, error) {
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, AppName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, fallback, AppName), nil
+}
+
+// LegacyPath returns ~/<name>, where versions before XDG support kept their files
+func LegacyPath(name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, name), nil
+}
+
+// DefaultConfigPath returns the default config file path: config.json in ConfigDir,
+// or the legacy ~/.patreon-posts.json while that exists and hasn't been migrated
+func DefaultConfigPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	legacy, err := LegacyPath(".patreon-posts.json")
+	if err != nil {
+		return "", err
+	}
+	return PreferExisting(filepath.Join(dir, "config.json"), legacy), nil
+}
+
+// MigrateLegacyConfig moves ~/.patreon-posts.json to DefaultConfigPath's XDG location
+// if only the legacy file exists. It returns the new path if the file was moved.
+func MigrateLegacyConfig() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	legacy, err := LegacyPath(".patreon-posts.json")
+	if err != nil {
+		return "", err
+	}
+	return MigrateFile(legacy, filepath.Join(dir, "config.json"))
+}
+
+// ProfileDBPath returns the default database path for a named profile, profiles/<profile>.db
+// in the XDG data directory or its legacy ~/.patreon-posts-<profile>.db. An empty profile
+// gives the default account's patreon-posts.db in the data directory or ~/.patreon-posts.db.
+func ProfileDBPath(profile string) (string, error) {
+	target, legacy, err := dbPaths(profile)
+	if err != nil {
+		return "", err
+	}
+	return PreferExisting(target, legacy), nil
+}
+
+// MigrateLegacyDB moves the profile's legacy database in the home directory to its XDG
+// location if only the legacy database exists. It returns the new path if it was moved.
+func MigrateLegacyDB(profile string) (string, error) {
+	target, legacy, err := dbPaths(profile)
+	if err != nil {
+		return "", err
+	}
+	return MigrateFile(legacy, target)
+}
+
+// dbPaths returns the XDG and legacy default database paths of a profile, or of the default account if it's empty
+func dbPaths(profile string) (target, legacy string, err error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", "", err
+	}
+	if profile == "" {
+		legacy, err = LegacyPath(".patreon-posts.db")
+		return filepath.Join(dir, "patreon-posts.db"), legacy, err
+	}
+
+	if err := CheckProfileName(profile); err != nil {
+		return "", "", err
+	}
+	legacy, err = LegacyPath(".patreon-posts-" + profile + ".db")
+	return filepath.Join(dir, "profiles", profile+".db"), legacy, err
+}
+
+// PreferExisting returns target, unless only legacy exists
+func PreferExisting(target, legacy string) string {
+	if fileExists(target) || !fileExists(legacy) {
+		return target
+	}
+	return legacy
+}
+
+// MigrateFile moves legacy to target, along with any SQLite -wal and -shm files next to it,
+// if legacy exists and target doesn't. It returns target if the file was moved.
+func MigrateFile(legacy, target string) (string, error) {
+	if fileExists(target) || !fileExists(legacy) {
+		return "", nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		return "", fmt.Errorf("failed to migrate %s: %w", legacy, err)
+	}
+	for _, suffix := range []string{"-wal", "-shm", ""} {
+		// The main file goes last, so an interrupted migration is retried on the next run
+		err := moveFile(legacy+suffix, target+suffix)
+		if err != nil && !(suffix != "" && errors.Is(err, os.ErrNotExist)) {
+			return "", fmt.Errorf("failed to migrate %s: %w", legacy, err)
+		}
+	}
+	return target, nil
+}
+
+// moveFile renames src to dst, copying when they are on different file systems
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
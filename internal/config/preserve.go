@@ -0,0 +1,413 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// member is a key and value of a JSON object, located by byte offsets into the object's text
+type member struct {
+	key        string
+	keyStart   int // Offset of the key's opening quote
+	valueStart int
+	valueEnd   int
+}
+
+// edit replaces data[start:end] with text
+type edit struct {
+	start, end int
+	text       []byte
+}
+
+// patchJSON rewrites the JSON object old so it holds the values of updated, an encoding of
+// a value of type t, while keeping old's formatting, key order and any keys t doesn't know.
+// Values that are unchanged keep their original text, as do unchanged array elements;
+// changed values are re-indented to match their surroundings; new keys are appended to
+// their object.
+func patchJSON(old, updated []byte, t reflect.Type) ([]byte, error) {
+	unit := indentUnit(old)
+	return patchObject(old, updated, t, "", unit)
+}
+
+// patchObject patches one object; base is the indentation of the line the object starts on
+func patchObject(old, updated []byte, t reflect.Type, base, unit string) ([]byte, error) {
+	oldMembers, open, closing, err := objectMembers(old)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(updated, &values); err != nil {
+		return nil, err
+	}
+	indent := memberIndent(old, oldMembers, open, base, unit)
+	multi := multiline(old, open, closing)
+
+	// Replace changed values and remove deleted keys
+	var edits []edit
+	firstKept := -1
+	for i, m := range oldMembers {
+		fieldType, known := memberType(t, m.key)
+		value, present := values[m.key]
+		oldValue := old[m.valueStart:m.valueEnd]
+		switch {
+		case !known || jsonEqual(oldValue, value):
+			// Unknown keys and unchanged values are kept as written
+		case !present:
+			if firstKept >= 0 {
+				// Leading removed members are dropped together below
+				edits = append(edits, edit{start: oldMembers[i-1].valueEnd, end: m.valueEnd})
+			}
+			continue
+		case isObject(oldValue) && isObject(value) && (fieldType.Kind() == reflect.Struct || fieldType.Kind() == reflect.Map):
+			patched, err := patchObject(oldValue, value, fieldType, indent, unit)
+			if err != nil {
+				return nil, err
+			}
+			edits = append(edits, edit{start: m.valueStart, end: m.valueEnd, text: patched})
+		case isArray(oldValue) && isArray(value) && fieldType.Kind() == reflect.Slice:
+			patched, err := patchArray(oldValue, value, fieldType.Elem(), indent, unit)
+			if err != nil {
+				return nil, err
+			}
+			edits = append(edits, edit{start: m.valueStart, end: m.valueEnd, text: patched})
+		default:
+			edits = append(edits, edit{start: m.valueStart, end: m.valueEnd, text: formatValue(value, indent, unit, multi)})
+		}
+		if firstKept < 0 {
+			firstKept = i
+			if i > 0 {
+				// Leading members were removed; drop them through to this member's key
+				edits = append(edits, edit{start: oldMembers[0].keyStart, end: m.keyStart})
+			}
+		}
+	}
+	if firstKept < 0 && len(oldMembers) > 0 {
+		edits = append(edits[:0], edit{start: open + 1, end: closing})
+	}
+	result := applyEdits(old, edits)
+
+	// Append keys the old object didn't have, in the order of t's fields
+	var added []string
+	for _, key := range orderedKeys(t, values) {
+		if _, ok := findMember(oldMembers, key); !ok && !isZeroJSON(values[key]) {
+			added = append(added, key)
+		}
+	}
+	if len(added) == 0 {
+		return result, nil
+	}
+	remaining, open, closing, err := objectMembers(result)
+	if err != nil {
+		return nil, err
+	}
+	// An empty object is opened up, as it would be by MarshalIndent
+	multi = multi || len(remaining) == 0
+	var insert bytes.Buffer
+	for i, key := range added {
+		if len(remaining) > 0 || i > 0 {
+			insert.WriteString(",")
+		}
+		if multi {
+			insert.WriteString("\n" + indent)
+		} else {
+			insert.WriteString(" ")
+		}
+		name, _ := json.Marshal(key)
+		insert.Write(name)
+		insert.WriteString(": ")
+		insert.Write(formatValue(values[key], indent, unit, multi))
+	}
+	at := closing
+	if len(remaining) > 0 {
+		at = remaining[len(remaining)-1].valueEnd
+	} else {
+		// Drop whatever whitespace the empty object held
+		result = append(result[:open+1:open+1], result[closing:]...)
+		at = open + 1
+	}
+	if multi && len(remaining) == 0 {
+		insert.WriteString("\n" + base)
+	}
+	return applyEdits(result, []edit{{start: at, end: at, text: insert.Bytes()}}), nil
+}
+
+// patchArray patches one array; base is the indentation of the line the array starts on.
+// Elements that are unchanged keep their original text, elements that changed in place,
+// such as a renamed campaign, are patched, and removed elements are dropped. The array is
+// laid out again with one element per line if it was written over several lines.
+func patchArray(old, updated []byte, t reflect.Type, base, unit string) ([]byte, error) {
+	elements, open, closing, err := arrayElements(old)
+	if err != nil {
+		return nil, err
+	}
+	var values []json.RawMessage
+	if err := json.Unmarshal(updated, &values); err != nil {
+		return nil, err
+	}
+	multi := multiline(old, open, closing)
+	indent := base + unit
+	if len(elements) > 0 {
+		indent = memberIndent(old, []member{{keyStart: elements[0].start}}, open, base, unit)
+	}
+	// New elements are written like the existing ones, on one line or indented
+	multiElement := multi && (len(elements) == 0 || bytes.IndexByte(old[elements[0].start:elements[0].end], '\n') >= 0)
+
+	var texts [][]byte
+	next := 0
+	for i, value := range values {
+		if k := findElement(old, elements[next:], value); k >= 0 {
+			texts = append(texts, old[elements[next+k].start:elements[next+k].end])
+			next += k + 1
+			continue
+		}
+		// An element that changed in place is patched unless the next value is the old element
+		if next < len(elements) && (i+1 >= len(values) || !jsonEqual(old[elements[next].start:elements[next].end], values[i+1])) {
+			oldValue := old[elements[next].start:elements[next].end]
+			next++
+			if isObject(oldValue) && isObject(value) && (t.Kind() == reflect.Struct || t.Kind() == reflect.Map) {
+				patched, err := patchObject(oldValue, value, t, indent, unit)
+				if err != nil {
+					return nil, err
+				}
+				texts = append(texts, patched)
+				continue
+			}
+		}
+		texts = append(texts, formatValue(value, indent, unit, multiElement))
+	}
+
+	if !multi || len(texts) == 0 {
+		return append(append([]byte("["), bytes.Join(texts, []byte(", "))...), ']'), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteString("[\n" + indent)
+	buf.Write(bytes.Join(texts, []byte(",\n"+indent)))
+	buf.WriteString("\n" + base + "]")
+	return buf.Bytes(), nil
+}
+
+// span locates an array element by byte offsets into the array's text
+type span struct {
+	start, end int
+}
+
+// arrayElements parses a JSON array, returning its elements and the offsets of its brackets
+func arrayElements(data []byte) (elements []span, open, closing int, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, 0, 0, fmt.Errorf("expected a JSON array")
+	}
+	open = int(dec.InputOffset()) - 1
+	for dec.More() {
+		start := skipSpace(data, int(dec.InputOffset()), ",")
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, 0, 0, err
+		}
+		elements = append(elements, span{start: start, end: int(dec.InputOffset())})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, 0, 0, err
+	}
+	return elements, open, int(dec.InputOffset()) - 1, nil
+}
+
+// findElement returns the index of the first element equal to value, or -1
+func findElement(data []byte, elements []span, value json.RawMessage) int {
+	for i, e := range elements {
+		if jsonEqual(data[e.start:e.end], value) {
+			return i
+		}
+	}
+	return -1
+}
+
+// objectMembers parses a JSON object, returning its members and the offsets of its braces
+func objectMembers(data []byte) (members []member, open, closing int, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, 0, 0, fmt.Errorf("expected a JSON object")
+	}
+	open = int(dec.InputOffset()) - 1
+	for dec.More() {
+		keyStart := skipSpace(data, int(dec.InputOffset()), ",")
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		key, _ := tok.(string)
+		valueStart := skipSpace(data, int(dec.InputOffset()), ":")
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, 0, 0, err
+		}
+		members = append(members, member{key: key, keyStart: keyStart, valueStart: valueStart, valueEnd: int(dec.InputOffset())})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, 0, 0, err
+	}
+	return members, open, int(dec.InputOffset()) - 1, nil
+}
+
+// skipSpace returns the offset of the first byte at or after i that is neither whitespace nor in extra
+func skipSpace(data []byte, i int, extra string) int {
+	for i < len(data) && (strings.IndexByte(" \t\r\n", data[i]) >= 0 || strings.IndexByte(extra, data[i]) >= 0) {
+		i++
+	}
+	return i
+}
+
+// applyEdits applies non-overlapping edits to data
+func applyEdits(data []byte, edits []edit) []byte {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	result := append([]byte(nil), data...)
+	for _, e := range edits {
+		result = append(result[:e.start], append(append([]byte(nil), e.text...), result[e.end:]...)...)
+	}
+	return result
+}
+
+// formatValue indents a JSON value for a member at the given indentation
+func formatValue(value json.RawMessage, indent, unit string, multi bool) []byte {
+	var buf bytes.Buffer
+	if !multi {
+		return oneLine(value)
+	}
+	json.Indent(&buf, value, indent, unit)
+	return buf.Bytes()
+}
+
+// oneLine formats a JSON value on a single line with a space after each colon and comma,
+// e.g. {"id": "1", "name": "One"}
+func oneLine(value json.RawMessage) []byte {
+	var buf bytes.Buffer
+	if json.Indent(&buf, value, "", "") != nil {
+		return value
+	}
+	// Strings can't hold raw newlines, so every newline is layout added by Indent
+	return []byte(strings.NewReplacer("{\n", "{", "[\n", "[", "\n}", "}", "\n]", "]", "\n", " ").Replace(buf.String()))
+}
+
+// indentUnit guesses the file's indentation from its first indented line, defaulting to two spaces
+func indentUnit(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n")[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
+
+// memberIndent returns the indentation of an object's members: that of its first member,
+// or one unit deeper than the object itself if it has none
+func memberIndent(data []byte, members []member, open int, base, unit string) string {
+	if len(members) > 0 {
+		start := members[0].keyStart
+		line := bytes.LastIndexByte(data[:start], '\n')
+		if line >= open {
+			return string(data[line+1 : start])
+		}
+	}
+	return base + unit
+}
+
+// multiline reports whether an object is written over several lines
+func multiline(data []byte, open, closing int) bool {
+	return bytes.IndexByte(data[open:closing], '\n') >= 0
+}
+
+// memberType returns the type of an object member and whether t declares it. Every key of a map is known.
+func memberType(t reflect.Type, key string) (reflect.Type, bool) {
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem(), true
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if jsonKey(t.Field(i)) == key {
+				return t.Field(i).Type, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// orderedKeys returns the keys of values in t's field order, or alphabetically for maps
+func orderedKeys(t reflect.Type, values map[string]json.RawMessage) []string {
+	if t.Kind() != reflect.Struct {
+		return sortedKeys(values)
+	}
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		if key := jsonKey(t.Field(i)); key != "" {
+			if _, ok := values[key]; ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+func findMember(members []member, key string) (member, bool) {
+	for _, m := range members {
+		if m.key == key {
+			return m, true
+		}
+	}
+	return member{}, false
+}
+
+// jsonEqual reports whether two JSON values are equal, treating a missing value (nil)
+// as equal to a zero value such as 0, "", false, [] or {}
+func jsonEqual(a, b []byte) bool {
+	if a == nil || b == nil {
+		return isZeroJSON(a) && isZeroJSON(b)
+	}
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// isZeroJSON reports whether a JSON value is missing, null or the zero value of its type
+func isZeroJSON(data []byte) bool {
+	if data == nil {
+		return true
+	}
+	var v any
+	if json.Unmarshal(data, &v) != nil {
+		return false
+	}
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+	return false
+}
+
+// isArray reports whether a JSON value is an array
+func isArray(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// isObject reports whether a JSON value is an object
+func isObject(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
@@ -0,0 +1,257 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPatchJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		old    string
+		change func(*Config)
+		want   string
+	}{
+		{
+			name: "add keeps unknown keys and element formatting",
+			old: `{
+  "cookies": "",
+  "future_key": {"a": 1},
+  "campaigns": [
+    {"id": "1", "name": "One"}
+  ]
+}
+`,
+			change: func(c *Config) { c.AddCampaign("2", "Two") },
+			want: `{
+  "cookies": "",
+  "future_key": {"a": 1},
+  "campaigns": [
+    {"id": "1", "name": "One"},
+    {"id": "2", "name": "Two"}
+  ]
+}
+`,
+		},
+		{
+			name:   "remove with tab indentation",
+			old:    "{\n\t\"cookies\": \"\",\n\t\"campaigns\": [\n\t\t{\"id\": \"1\", \"name\": \"One\"},\n\t\t{\"id\": \"2\", \"name\": \"Two\"},\n\t\t{\"id\": \"3\", \"name\": \"Three\"}\n\t]\n}\n",
+			change: func(c *Config) { c.RemoveCampaign("2") },
+			want:   "{\n\t\"cookies\": \"\",\n\t\"campaigns\": [\n\t\t{\"id\": \"1\", \"name\": \"One\"},\n\t\t{\"id\": \"3\", \"name\": \"Three\"}\n\t],\n\t\"deleted_campaigns\": [\n\t\t\"2\"\n\t]\n}\n",
+		},
+		{
+			name: "remove the last campaign",
+			old: `{
+  "campaigns": [
+    {"id": "1", "name": "One"}
+  ],
+  "cookies": ""
+}
+`,
+			change: func(c *Config) { c.Campaigns = nil },
+			want: `{
+  "cookies": ""
+}
+`,
+		},
+		{
+			name:   "rename in a compact file",
+			old:    `{"cookies": "", "campaigns": [{"id": "1", "name": "One", "page_size": 10}, {"id":"2","name":"Two"}]}`,
+			change: func(c *Config) { c.AddCampaign("1", "Uno") },
+			want:   `{"cookies": "", "campaigns": [{"id": "1", "name": "Uno", "page_size": 10}, {"id":"2","name":"Two"}]}`,
+		},
+		{
+			name: "rename keeps the campaign's layout",
+			old: `{
+    "campaigns": [
+        {
+            "id": "1",
+            "name": "One",
+            "post_types": ["video_embed"]
+        }
+    ]
+}`,
+			change: func(c *Config) { c.AddCampaign("1", "Uno") },
+			want: `{
+    "campaigns": [
+        {
+            "id": "1",
+            "name": "Uno",
+            "post_types": ["video_embed"]
+        }
+    ]
+}`,
+		},
+		{
+			name: "profile campaigns",
+			old: `{
+  "cookies": "",
+  "profiles": {
+    "sam": {
+      "cookies": "x"
+    },
+    "alex": {"cookies": "y"}
+  }
+}
+`,
+			change: func(c *Config) {
+				p := c.Profiles["sam"]
+				p.Campaigns = []Campaign{{ID: "5", Name: "Five"}}
+				c.Profiles["sam"] = p
+			},
+			want: `{
+  "cookies": "",
+  "profiles": {
+    "sam": {
+      "cookies": "x",
+      "campaigns": [
+        {
+          "id": "5",
+          "name": "Five"
+        }
+      ]
+    },
+    "alex": {"cookies": "y"}
+  }
+}
+`,
+		},
+		{
+			name:   "empty object",
+			old:    `{}`,
+			change: func(c *Config) { c.AddCampaign("1", "One") },
+			want: `{
+  "campaigns": [
+    {
+      "id": "1",
+      "name": "One"
+    }
+  ]
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			if err := json.Unmarshal([]byte(tt.old), &cfg); err != nil {
+				t.Fatal(err)
+			}
+			tt.change(&cfg)
+			updated, err := json.MarshalIndent(&cfg, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := patchJSON([]byte(tt.old), updated, reflect.TypeOf(cfg))
+			if err != nil {
+				t.Fatalf("patchJSON: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("patchJSON() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveCampaigns(t *testing.T) {
+	const file = `{
+  "cookies": "",
+  "published_after": "2024-01-01",
+  "future_key": true,
+  "campaigns": [
+    {"id": "1", "name": "One"}
+  ],
+  "profiles": {
+    "sam": {
+      "campaigns": [
+        {"id": "7", "name": "Seven"}
+      ]
+    }
+  }
+}
+`
+	tests := []struct {
+		name    string
+		profile string
+		change  func(*Config)
+		want    string
+	}{
+		{
+			name:   "top level",
+			change: func(c *Config) { c.RemoveCampaign("1"); c.AddCampaign("2", "Two") },
+			want: `{
+  "cookies": "",
+  "published_after": "2024-01-01",
+  "future_key": true,
+  "campaigns": [
+    {"id": "2", "name": "Two"}
+  ],
+  "profiles": {
+    "sam": {
+      "campaigns": [
+        {"id": "7", "name": "Seven"}
+      ]
+    }
+  },
+  "deleted_campaigns": [
+    "1"
+  ]
+}
+`,
+		},
+		{
+			name:    "profile",
+			profile: "sam",
+			change:  func(c *Config) { c.AddCampaign("7", "Sept") },
+			want: `{
+  "cookies": "",
+  "published_after": "2024-01-01",
+  "future_key": true,
+  "campaigns": [
+    {"id": "1", "name": "One"}
+  ],
+  "profiles": {
+    "sam": {
+      "campaigns": [
+        {"id": "7", "name": "Sept"}
+      ]
+    }
+  }
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.profile != "" {
+				if cfg, err = cfg.WithProfile(tt.profile); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// Layered values must not reach the file
+			cfg.PublishedAfter = "2020-01-01"
+			tt.change(cfg)
+
+			if err := SaveCampaigns(path, cfg); err != nil {
+				t.Fatalf("SaveCampaigns: %v", err)
+			}
+			got, _ := os.ReadFile(path)
+			if string(got) != tt.want {
+				t.Errorf("file =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile holds the settings of one Patreon account. Set fields replace the
+// top-level ones when the profile is selected.
+type Profile struct {
+	Cookies          string     `json:"cookies,omitempty"`
+	CookiesEnv       string     `json:"cookies_env,omitempty"`
+	CookiesFile      string     `json:"cookies_file,omitempty"`
+	CookiesCommand   string     `json:"cookies_command,omitempty"`
+	CookieSource     string     `json:"cookie_source,omitempty"`
+	Campaigns        []Campaign `json:"campaigns,omitempty"`
+	DeletedCampaigns []string   `json:"deleted_campaigns,omitempty"`
+	PublishedAfter   string     `json:"published_after,omitempty"`
+	DBPath           string     `json:"db_path,omitempty"`      // SQLite database for this account (default: profiles/<name>.db in the XDG data directory)
+	DownloadDir      string     `json:"download_dir,omitempty"` // Overrides download_dir
+	ArchiveDir       string     `json:"archive_dir,omitempty"`  // Overrides archive_dir
+}
+
+// hasCookies reports whether the profile sets any cookie option
+func (p Profile) hasCookies() bool {
+	return p.Cookies != "" || p.CookiesEnv != "" || p.CookiesFile != "" || p.CookiesCommand != "" || p.CookieSource != ""
+}
+
+// CheckProfileName returns an error if a profile's name can't be used for its default database file
+func CheckProfileName(name string) error {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("profile name %q can't be used in a file name, set db_path for it", name)
+	}
+	return nil
+}
+
+// ProfileNames returns the configured profile names in alphabetical order
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithProfile returns a copy of the config with the named profile's settings applied.
+// An empty name selects default_profile, or returns the config unchanged if that isn't set.
+func (c *Config) WithProfile(name string) (*Config, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return c, nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile %q: no profiles are configured", name)
+		}
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+
+	merged := *c
+	merged.Profile = name
+	merged.sources = make(map[string]string, len(c.sources))
+	for key, source := range c.sources {
+		merged.sources[key] = source
+	}
+	source := "profile " + name
+
+	if p.hasCookies() {
+		// Cookie options are replaced as a group so another account's cookies never leak in
+		merged.Cookies = p.Cookies
+		merged.CookiesEnv = p.CookiesEnv
+		merged.CookiesFile = p.CookiesFile
+		merged.CookiesCommand = p.CookiesCommand
+		merged.CookieSource = p.CookieSource
+		for _, key := range cookieKeys {
+			merged.setSource(key, source)
+		}
+	}
+	// Each account has its own campaigns and database, even if it sets neither
+	merged.Campaigns = p.Campaigns
+	merged.DeletedCampaigns = p.DeletedCampaigns
+	merged.setSource("campaigns", source)
+	merged.setSource("deleted_campaigns", source)
+	merged.DBPath = p.DBPath
+	delete(merged.sources, "db_path")
+	if p.DBPath != "" {
+		merged.setSource("db_path", source)
+	}
+	if p.PublishedAfter != "" {
+		merged.PublishedAfter = p.PublishedAfter
+		merged.setSource("published_after", source)
+	}
+	if p.DownloadDir != "" {
+		merged.DownloadDir = p.DownloadDir
+		merged.setSource("download_dir", source)
+	}
+	if p.ArchiveDir != "" {
+		merged.ArchiveDir = p.ArchiveDir
+		merged.setSource("archive_dir", source)
+	}
+	return &merged, nil
+}
+
+// SaveCampaigns writes the campaigns and deleted campaigns of cfg to the config file at
+// path, under cfg's profile if one is applied. Other settings in the file are left as
+// they are, so a config with a profile, environment variables or flags applied is never
+// written back.
+func SaveCampaigns(path string, cfg *Config) error {
+	file, err := LoadInsecure(path)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Profile == "" {
+		file.Campaigns = cfg.Campaigns
+		file.DeletedCampaigns = cfg.DeletedCampaigns
+	} else {
+		p, ok := file.Profiles[cfg.Profile]
+		if !ok {
+			return fmt.Errorf("unknown profile %q", cfg.Profile)
+		}
+		p.Campaigns = cfg.Campaigns
+		p.DeletedCampaigns = cfg.DeletedCampaigns
+		file.Profiles[cfg.Profile] = p
+	}
+	return Save(path, file)
+}
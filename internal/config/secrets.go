@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// commandTimeout bounds how long a cookies_command may run, including any passphrase prompt
+const commandTimeout = 2 * time.Minute
+
+// SecretProvider supplies the session cookies from somewhere other than the config file
+type SecretProvider interface {
+	// Name describes where the cookies come from, e.g. "environment variable PATREON_COOKIES"
+	Name() string
+	// Secret returns the Cookie header value
+	Secret() (string, error)
+}
+
+// CookieProvider returns the provider selected by cookies_env, cookies_file or cookies_command,
+// or nil if none is set. Setting more than one is an error.
+func (c *Config) CookieProvider() (SecretProvider, error) {
+	var providers []SecretProvider
+	if c.CookiesEnv != "" {
+		providers = append(providers, EnvSecret{Var: c.CookiesEnv})
+	}
+	if c.CookiesFile != "" {
+		providers = append(providers, FileSecret{Path: c.CookiesFile})
+	}
+	if c.CookiesCommand != "" {
+		providers = append(providers, CommandSecret{Command: c.CookiesCommand})
+	}
+
+	switch len(providers) {
+	case 0:
+		return nil, nil
+	case 1:
+		return providers[0], nil
+	}
+	return nil, errors.New("only one of cookies_env, cookies_file and cookies_command can be set")
+}
+
+// EnvSecret reads the cookies from an environment variable
+type EnvSecret struct {
+	Var string
+}
+
+func (s EnvSecret) Name() string {
+	return "environment variable " + s.Var
+}
+
+func (s EnvSecret) Secret() (string, error) {
+	value := strings.TrimSpace(os.Getenv(s.Var))
+	if value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", s.Var)
+	}
+	return value, nil
+}
+
+// FileSecret reads the cookies from a file that only its owner can read
+type FileSecret struct {
+	Path string
+}
+
+func (s FileSecret) Name() string {
+	return "file " + s.Path
+}
+
+func (s FileSecret) Secret() (string, error) {
+	path := ExpandHome(s.Path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read cookies file: %w", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return "", fmt.Errorf("cookies file %s is readable by other users (mode %04o); run: chmod 600 %s",
+			path, info.Mode().Perm(), path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read cookies file: %w", err)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", fmt.Errorf("cookies file %s is empty", path)
+	}
+	return value, nil
+}
+
+// CommandSecret runs a command, such as "pass show patreon", and uses the first
+// line it prints as the cookies
+type CommandSecret struct {
+	Command string
+}
+
+func (s CommandSecret) Name() string {
+	return "command " + s.Command
+}
+
+func (s CommandSecret) Secret() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", s.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", s.Command)
+	}
+	// Password managers may prompt for a passphrase on the terminal
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	var out bytes.Buffer
+	cmd.Stdout = &out
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("cookies command failed: %w", err)
+	}
+
+	line, _, _ := strings.Cut(out.String(), "\n")
+	value := strings.TrimSpace(line)
+	if value == "" {
+		return "", errors.New("cookies command printed nothing")
+	}
+	return value, nil
+}
+
+// ExpandHome replaces a leading ~/ in path with the user's home directory
+func ExpandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
@@ -0,0 +1,394 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"patreon-posts/internal/cookies"
+	"patreon-posts/internal/links"
+)
+
+// Problem is a mistake in the config file, located by the JSON path of the offending value
+type Problem struct {
+	Path    string // e.g. "campaigns[2].id" or "profiles.sam.published_after"; empty for the whole file
+	Message string
+	Warning bool // The value is still used, possibly adjusted, rather than rejected
+}
+
+// String formats the problem as "path: message"
+func (p Problem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+// Problems is the result of Validate
+type Problems []Problem
+
+// HasErrors reports whether any problem is an error rather than a warning
+func (ps Problems) HasErrors() bool {
+	for _, p := range ps {
+		if !p.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns the first error as an error, or nil if there are only warnings
+func (ps Problems) Err() error {
+	for _, p := range ps {
+		if !p.Warning {
+			return errors.New(p.String())
+		}
+	}
+	return nil
+}
+
+// Validate checks the config as read from the file, before profiles, environment
+// variables and flags are applied. It reports keys the app doesn't know, such as
+// misspellings, along with invalid values. Values from environment variables and flags
+// are checked by Set.
+func (c *Config) Validate() Problems {
+	v := &validator{problems: append(Problems(nil), c.unknown...)}
+
+	v.campaigns("campaigns", c.Campaigns)
+	v.deletedCampaigns("deleted_campaigns", c.DeletedCampaigns, c.Campaigns)
+	v.date("published_after", c.PublishedAfter)
+	v.cookieOptions("", c.Cookies, c.CookiesEnv, c.CookiesFile, c.CookiesCommand, c.CookieSource)
+
+	if c.RequestDelayMinMs < 0 {
+		v.errorf("request_delay_min_ms", "must not be negative")
+	} else if c.RequestDelayMinMs > 0 && c.RequestDelayMinMs < 1000 {
+		v.warnf("request_delay_min_ms", "%d is below the minimum of 1000 and is raised to 1000", c.RequestDelayMinMs)
+	}
+	if c.RequestDelayMaxMs < 0 {
+		v.errorf("request_delay_max_ms", "must not be negative")
+	} else if c.RequestDelayMaxMs > 0 && c.RequestDelayMaxMs < c.GetRequestDelayMinMs() {
+		v.errorf("request_delay_max_ms", "%d is below request_delay_min_ms (%d)", c.RequestDelayMaxMs, c.GetRequestDelayMinMs())
+	}
+	v.notNegative("request_burst", c.RequestBurst)
+	if c.RequestTimeoutMs < -1 {
+		v.errorf("request_timeout_ms", "must be -1 (no timeout) or more")
+	}
+	if c.MaxRetries < -1 {
+		v.errorf("max_retries", "must be -1 (no retries) or more")
+	}
+	v.notNegative("retry_base_delay_ms", c.RetryBaseDelayMs)
+	v.notNegative("retry_max_delay_ms", c.RetryMaxDelayMs)
+	if c.RetryMaxDelayMs > 0 && c.RetryMaxDelayMs < c.GetRetryBaseDelayMs() {
+		v.errorf("retry_max_delay_ms", "%d is below retry_base_delay_ms (%d)", c.RetryMaxDelayMs, c.GetRetryBaseDelayMs())
+	}
+
+	v.url("api_base_url", c.APIBaseURL)
+	v.url("proxy_url", c.ProxyURL)
+
+	v.linkProviders("link_providers", c.LinkProviders)
+
+	for _, name := range c.ProfileNames() {
+		p := c.Profiles[name]
+		path := jsonPath("profiles", name)
+		if p.DBPath == "" {
+			if err := CheckProfileName(name); err != nil {
+				v.errorf(path, "%v", err)
+			}
+		}
+		v.campaigns(path+".campaigns", p.Campaigns)
+		v.deletedCampaigns(path+".deleted_campaigns", p.DeletedCampaigns, p.Campaigns)
+		v.date(path+".published_after", p.PublishedAfter)
+		v.cookieOptions(path, p.Cookies, p.CookiesEnv, p.CookiesFile, p.CookiesCommand, p.CookieSource)
+	}
+	if c.DefaultProfile != "" {
+		if _, ok := c.Profiles[c.DefaultProfile]; !ok {
+			v.errorf("default_profile", "no profile named %q", c.DefaultProfile)
+		}
+	}
+
+	return v.problems
+}
+
+// validator collects problems for Validate
+type validator struct {
+	problems Problems
+}
+
+func (v *validator) errorf(path, format string, args ...any) {
+	v.problems = append(v.problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warnf(path, format string, args ...any) {
+	v.problems = append(v.problems, Problem{Path: path, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+// campaigns checks that every campaign has a numeric ID that isn't listed twice, and its overrides
+func (v *validator) campaigns(path string, campaigns []Campaign) {
+	seen := make(map[string]int)
+	for i, campaign := range campaigns {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		v.date(itemPath+".published_after", campaign.PublishedAfter)
+		v.patterns(itemPath+".include_titles", campaign.IncludeTitles)
+		v.patterns(itemPath+".exclude_titles", campaign.ExcludeTitles)
+		for j, postType := range campaign.PostTypes {
+			if strings.TrimSpace(postType) == "" {
+				v.errorf(fmt.Sprintf("%s.post_types[%d]", itemPath, j), "must not be empty")
+			}
+		}
+		v.linkProviders(itemPath+".link_providers", campaign.LinkProviders)
+		v.notNegative(itemPath+".page_size", campaign.PageSize)
+		v.notNegative(itemPath+".request_delay_min_ms", campaign.RequestDelayMinMs)
+		v.notNegative(itemPath+".request_delay_max_ms", campaign.RequestDelayMaxMs)
+		if campaign.RequestDelayMinMs > 0 && campaign.RequestDelayMaxMs > 0 && campaign.RequestDelayMaxMs < campaign.RequestDelayMinMs {
+			v.errorf(itemPath+".request_delay_max_ms", "%d is below request_delay_min_ms (%d)", campaign.RequestDelayMaxMs, campaign.RequestDelayMinMs)
+		}
+
+		idPath := itemPath + ".id"
+		switch {
+		case campaign.ID == "":
+			v.errorf(idPath, "is required")
+		case strings.Trim(campaign.ID, "0123456789") != "":
+			v.errorf(idPath, "%q is not a campaign ID; IDs are numeric, use --add-campaign to look one up by name or URL", campaign.ID)
+		default:
+			if first, ok := seen[campaign.ID]; ok {
+				v.errorf(idPath, "duplicates %s[%d]", path, first)
+			} else {
+				seen[campaign.ID] = i
+			}
+		}
+	}
+}
+
+// deletedCampaigns checks that deleted campaign IDs are numeric and warns about any that
+// are still listed, since the campaigns list wins
+func (v *validator) deletedCampaigns(path string, deleted []string, campaigns []Campaign) {
+	for i, id := range deleted {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if id == "" || strings.Trim(id, "0123456789") != "" {
+			v.errorf(itemPath, "%q is not a campaign ID", id)
+			continue
+		}
+		for _, campaign := range campaigns {
+			if campaign.ID == id {
+				v.warnf(itemPath, "campaign %s is also in the campaigns list, which wins, so it isn't deleted", id)
+				break
+			}
+		}
+	}
+}
+
+// patterns checks that title patterns are valid regular expressions
+func (v *validator) patterns(path string, patterns []string) {
+	for i, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			v.errorf(fmt.Sprintf("%s[%d]", path, i), "invalid regular expression: %v", err)
+		}
+	}
+}
+
+// linkProviders checks that every key names a builtin link provider
+func (v *validator) linkProviders(path string, enabled map[string]bool) {
+	providers := links.NewDefaultRegistry(nil).Providers()
+	for _, key := range sortedKeys(enabled) {
+		if !contains(providers, key) {
+			v.errorf(jsonPath(path, key), "unknown provider (expected one of %s)", strings.Join(providers, ", "))
+		}
+	}
+}
+
+// date checks a YYYY-MM-DD date, if set
+func (v *validator) date(path, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		v.errorf(path, "%q is not a date in YYYY-MM-DD form", value)
+	}
+}
+
+// cookieOptions checks that at most one cookie provider is set and the cookie source is understood
+func (v *validator) cookieOptions(path, inline, env, file, command, source string) {
+	set := 0
+	for _, value := range []string{env, file, command} {
+		if value != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		v.errorf(path, "set only one of cookies_env, cookies_file and cookies_command")
+	}
+	if source != "" {
+		if _, err := cookies.ParseSource(source); err != nil {
+			v.errorf(join(path, "cookie_source"), "%v", err)
+		}
+	}
+	if inline != "" && (set > 0 || source != "") {
+		v.warnf(join(path, "cookies"), "ignored because another cookie option is set")
+	}
+}
+
+// url checks an absolute http or https URL, if set
+func (v *validator) url(path, value string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		v.errorf(path, "%q is not a valid URL", value)
+		return
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.errorf(path, "%q must be an http:// or https:// URL", value)
+	}
+}
+
+func (v *validator) notNegative(path string, value int) {
+	if value < 0 {
+		v.errorf(path, "must not be negative")
+	}
+}
+
+// unknownFields walks the JSON of a value of type t and reports object keys that t
+// has no field for, suggesting the key that was probably meant
+func unknownFields(data json.RawMessage, t reflect.Type, path string) []Problem {
+	var problems []Problem
+	switch t.Kind() {
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(data, &object) != nil {
+			return nil
+		}
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < t.NumField(); i++ {
+			if key := jsonKey(t.Field(i)); key != "" {
+				fields[key] = t.Field(i).Type
+			}
+		}
+		for _, key := range sortedKeys(object) {
+			fieldType, ok := fields[key]
+			if !ok {
+				message := "unknown key"
+				if guess := similarKey(key, fields); guess != "" {
+					message = fmt.Sprintf("unknown key, did you mean %q?", guess)
+				}
+				problems = append(problems, Problem{Path: join(path, key), Message: message})
+				continue
+			}
+			problems = append(problems, unknownFields(object[key], fieldType, join(path, key))...)
+		}
+	case reflect.Slice:
+		var items []json.RawMessage
+		if json.Unmarshal(data, &items) != nil {
+			return nil
+		}
+		for i, item := range items {
+			problems = append(problems, unknownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case reflect.Map:
+		var entries map[string]json.RawMessage
+		if json.Unmarshal(data, &entries) != nil {
+			return nil
+		}
+		for _, key := range sortedKeys(entries) {
+			problems = append(problems, unknownFields(entries[key], t.Elem(), jsonPath(path, key))...)
+		}
+	}
+	return problems
+}
+
+// similarKey returns the known key that differs from key only in case, underscores or
+// hyphens, e.g. "published_after" for "publishedAfter"
+func similarKey(key string, fields map[string]reflect.Type) string {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(s))
+	}
+	for field := range fields {
+		if normalize(field) == normalize(key) {
+			return field
+		}
+	}
+	return ""
+}
+
+// parseError describes a JSON syntax or type error with its line and column or key path
+func parseError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, col := position(data, syntaxErr.Offset)
+		return fmt.Errorf("line %d, column %d: %w", line, col, err)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fmt.Errorf("%s: expected %s, got JSON %s", fieldPath(typeErr.Field), typeErr.Type, typeErr.Value)
+	}
+	return err
+}
+
+// fieldPath converts the dotted field of a JSON type error, e.g. "campaigns.0.id", into
+// the path form Validate reports, e.g. "campaigns[0].id"
+func fieldPath(field string) string {
+	var path string
+	for _, part := range strings.Split(field, ".") {
+		if part != "" && strings.Trim(part, "0123456789") == "" {
+			path += "[" + part + "]"
+		} else {
+			path = join(path, part)
+		}
+	}
+	return path
+}
+
+// position converts a byte offset into a 1-based line and column
+func position(data []byte, offset int64) (line, col int) {
+	line, col = 1, 1
+	for i := int64(0); i < offset-1 && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// join appends a key to a JSON path
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// jsonPath appends a map key to a JSON path, quoting keys that aren't plain names
+func jsonPath(path, key string) string {
+	if key != "" && strings.Trim(key, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-") == "" {
+		return join(path, key)
+	}
+	return fmt.Sprintf("%s[%q]", path, key)
+}
+
+// sortedKeys returns the keys of m in alphabetical order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
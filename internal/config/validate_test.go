@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"patreon-posts/internal/links"
+)
+
+// loadString loads a config file holding content
+func loadString(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return Load(path)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want []string
+	}{
+		{
+			name: "valid",
+			json: `{"campaigns": [{"id": "123"}], "published_after": "2024-01-31", "request_timeout_ms": -1}`,
+		},
+		{
+			name: "unknown keys with hints",
+			json: `{"publishedAfter": "2024-01-01", "campaigns": [{"id": "1", "nmae": "x"}], "profiles": {"../sam": {"Cookies": ""}}}`,
+			want: []string{
+				`campaigns[0].nmae: unknown key`,
+				`profiles["../sam"].Cookies: unknown key, did you mean "cookies"?`,
+				`publishedAfter: unknown key, did you mean "published_after"?`,
+				`profiles["../sam"]: profile name "../sam" can't be used in a file name, set db_path for it`,
+			},
+		},
+		{
+			name: "campaign IDs",
+			json: `{"campaigns": [{"id": "12"}, {"id": "hatfilms"}, {}, {"id": "12"}], "deleted_campaigns": ["12", "x"]}`,
+			want: []string{
+				`campaigns[1].id: "hatfilms" is not a campaign ID; IDs are numeric, use --add-campaign to look one up by name or URL`,
+				`campaigns[2].id: is required`,
+				`campaigns[3].id: duplicates campaigns[0]`,
+				`deleted_campaigns[0]: campaign 12 is also in the campaigns list, which wins, so it isn't deleted`,
+				`deleted_campaigns[1]: "x" is not a campaign ID`,
+			},
+		},
+		{
+			name: "values",
+			json: `{"published_after": "2024-13-01", "request_timeout_ms": -2, "request_delay_min_ms": 10, "proxy_url": "socks5://x", "link_providers": {"vimeo": true, "myspace": true}}`,
+			want: []string{
+				`published_after: "2024-13-01" is not a date in YYYY-MM-DD form`,
+				`request_delay_min_ms: 10 is below the minimum of 1000 and is raised to 1000`,
+				`request_timeout_ms: must be -1 (no timeout) or more`,
+				`proxy_url: "socks5://x" must be an http:// or https:// URL`,
+				`link_providers.myspace: unknown provider (expected one of ` + strings.Join(links.NewDefaultRegistry(nil).Providers(), ", ") + `)`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadString(t, tt.json)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, p := range cfg.Validate() {
+				got = append(got, p.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestLoadReportsParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"syntax", "{\n  \"campaigns\": [\n    {\"id\": \"1\",}\n  ]\n}", "line 3, column 16: "},
+		{"type", `{"campaigns": [{"id": 1}]}`, "campaigns[0].id: expected string, got JSON number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadString(t, tt.json)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
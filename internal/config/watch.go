@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// pollInterval is how often the file is read when changes to it can't be watched
+const pollInterval = 2 * time.Second
+
+// settleDelay is how long the file must be left alone before a change is reported,
+// so an editor saving in several writes causes a single reload
+const settleDelay = 200 * time.Millisecond
+
+// written holds what Save last wrote to each config file, so watchers can tell the app's
+// own writes from edits made elsewhere
+var written = struct {
+	sync.Mutex
+	content map[string][]byte
+}{content: make(map[string][]byte)}
+
+// recordWrite records the content Save is writing to path; nil forgets it
+func recordWrite(path string, data []byte) {
+	written.Lock()
+	defer written.Unlock()
+	key, _ := filepath.Abs(path)
+	if data == nil {
+		delete(written.content, key)
+		return
+	}
+	written.content[key] = data
+}
+
+// wroteContent reports whether Save last wrote exactly data to path
+func wroteContent(path string, data []byte) bool {
+	written.Lock()
+	defer written.Unlock()
+	key, _ := filepath.Abs(path)
+	saved, ok := written.content[key]
+	return ok && bytes.Equal(saved, data)
+}
+
+// Watcher reports changes to a config file. Editors and Save replace the file rather than
+// writing to it, so the file's directory is watched and the file's content is compared
+// with what it held before. Where the directory can't be watched, the file is polled.
+// Changes written by Save in this process, such as campaign edits in the TUI, aren't
+// reported, since the app already has them.
+type Watcher struct {
+	path      string
+	changes   chan struct{}
+	stop      chan struct{}
+	closer    io.Closer // Stops watching the directory; nil when polling
+	closeOnce sync.Once
+}
+
+// Watch starts watching the config file at path, which doesn't have to exist yet
+func Watch(path string) *Watcher {
+	w := &Watcher{
+		path:    path,
+		changes: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+	last, _ := os.ReadFile(path)
+
+	// Without a watch, events is nil and run polls the file instead
+	events, closer, _ := watchDir(filepath.Dir(path), filepath.Base(path))
+	w.closer = closer
+	go w.run(events, last)
+	return w
+}
+
+// Changes delivers a value whenever the file's content changes. Changes made while the
+// previous one hasn't been received yet are reported once. It is closed by Close.
+func (w *Watcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Close stops watching the file
+func (w *Watcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.stop)
+		if w.closer != nil {
+			err = w.closer.Close()
+		}
+	})
+	return err
+}
+
+// run waits for the file to change until the watcher is closed. events signals that the
+// file's directory changed; without it, or once it fails, the file is polled instead.
+func (w *Watcher) run(events <-chan struct{}, last []byte) {
+	defer close(w.changes)
+
+	var poll <-chan time.Time
+	if events == nil {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	var settle <-chan time.Time
+	for {
+		select {
+		case <-w.stop:
+			return
+		case _, ok := <-events:
+			if !ok {
+				// The watch failed; poll from now on
+				events = nil
+				ticker := time.NewTicker(pollInterval)
+				defer ticker.Stop()
+				poll = ticker.C
+				continue
+			}
+			settle = time.After(settleDelay)
+		case <-settle:
+			settle = nil
+			last = w.check(last)
+		case <-poll:
+			last = w.check(last)
+		}
+	}
+}
+
+// check reports a change if the file's content differs from last, returning its content.
+// A file that can't be read, such as one being replaced, is checked again next time.
+func (w *Watcher) check(last []byte) []byte {
+	data, err := os.ReadFile(w.path)
+	if err != nil || bytes.Equal(data, last) {
+		return last
+	}
+	if wroteContent(w.path, data) {
+		// The app's own write
+		return data
+	}
+	select {
+	case w.changes <- struct{}{}:
+	default:
+		// A change is already waiting to be received
+	}
+	return data
+}
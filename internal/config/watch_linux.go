@@ -0,0 +1,68 @@
+//go:build linux
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// watchMask selects the inotify events that can change a file in the watched directory
+const watchMask = unix.IN_CLOSE_WRITE | unix.IN_MODIFY | unix.IN_CREATE | unix.IN_MOVED_TO | unix.IN_DELETE | unix.IN_MOVED_FROM
+
+// watchDir uses inotify to signal events for the file called name in dir. The returned
+// channel is closed if reading events fails, and the closer stops the watch.
+func watchDir(dir, name string) (<-chan struct{}, io.Closer, error) {
+	// A non-blocking descriptor lets the runtime poller wake a pending read on Close
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+	if _, err := unix.InotifyAddWatch(fd, dir, watchMask); err != nil {
+		unix.Close(fd)
+		return nil, nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+	file := os.NewFile(uintptr(fd), "inotify")
+
+	events := make(chan struct{}, 1)
+	go func() {
+		defer close(events)
+		buf := make([]byte, 64*1024)
+		for {
+			n, err := file.Read(buf)
+			if err != nil {
+				return
+			}
+			if namesFile(buf[:n], name) {
+				select {
+				case events <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return events, file, nil
+}
+
+// namesFile reports whether a buffer of inotify events includes one for the file called
+// name, or an overflow that may have hidden one
+func namesFile(buf []byte, name string) bool {
+	for offset := 0; offset+unix.SizeofInotifyEvent <= len(buf); {
+		event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		start := offset + unix.SizeofInotifyEvent
+		end := start + int(event.Len)
+		if end > len(buf) {
+			return false
+		}
+		if event.Mask&unix.IN_Q_OVERFLOW != 0 || string(bytes.TrimRight(buf[start:end], "\x00")) == name {
+			return true
+		}
+		offset = end
+	}
+	return false
+}
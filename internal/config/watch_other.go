@@ -0,0 +1,13 @@
+//go:build !linux
+
+package config
+
+import (
+	"errors"
+	"io"
+)
+
+// watchDir isn't supported here, so the watcher polls the file instead
+func watchDir(dir, name string) (<-chan struct{}, io.Closer, error) {
+	return nil, nil, errors.ErrUnsupported
+}
@@ -0,0 +1,159 @@
+package cookies
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// chromiumSource reads the Cookies database of a Chromium-based browser.
+// Only unencrypted values can be read; current Chrome builds encrypt every cookie
+// with a key kept in the OS keychain, in which case a cookies.txt export is needed.
+type chromiumSource struct {
+	browser string // chrome, chromium, brave or edge
+	path    string // Cookies file; empty for the browser's default profile
+}
+
+// ErrEncrypted is returned when every matching cookie in a Chromium database is encrypted
+var ErrEncrypted = errors.New("cookies are encrypted by the browser; export a cookies.txt file instead")
+
+// chromiumEpoch is the start of Chromium's timestamps, which count microseconds from 1601
+var chromiumEpoch = time.Date(1601, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func (s chromiumSource) Name() string {
+	name := map[string]string{"chrome": "Chrome", "chromium": "Chromium", "brave": "Brave", "edge": "Edge"}[s.browser]
+	if s.path == "" {
+		return name
+	}
+	return name + " (" + s.path + ")"
+}
+
+func (s chromiumSource) Cookies(domain string) ([]Cookie, error) {
+	path, err := s.database()
+	if err != nil {
+		return nil, err
+	}
+
+	copied, cleanup, err := copyDatabase(path)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	db, err := sql.Open("sqlite", copied)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT host_key, path, name, value, length(encrypted_value), is_secure, expires_utc
+		FROM cookies
+		WHERE host_key = ? OR host_key LIKE ?
+	`, domain, "%."+domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", path, err)
+	}
+	defer rows.Close()
+
+	var cookies []Cookie
+	encrypted := 0
+	for rows.Next() {
+		var c Cookie
+		var encryptedLen, expires int64
+		if err := rows.Scan(&c.Domain, &c.Path, &c.Name, &c.Value, &encryptedLen, &c.Secure, &expires); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if c.Value == "" && encryptedLen > 0 {
+			encrypted++
+			continue
+		}
+		if expires > 0 {
+			c.Expires = chromiumEpoch.Add(time.Duration(expires) * time.Microsecond)
+		}
+		cookies = append(cookies, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(cookies) == 0 && encrypted > 0 {
+		return nil, ErrEncrypted
+	}
+	return cookies, nil
+}
+
+// database returns the Cookies file to read
+func (s chromiumSource) database() (string, error) {
+	if s.path != "" {
+		if info, err := os.Stat(s.path); err == nil && info.IsDir() {
+			return chromiumCookiesFile(s.path)
+		}
+		return s.path, nil
+	}
+	for _, dir := range chromiumProfileDirs(s.browser) {
+		if path, err := chromiumCookiesFile(dir); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no %s profile found, pass %s:<Cookies file>", s.Name(), s.browser)
+}
+
+// chromiumCookiesFile finds the Cookies database in a profile directory, which
+// moved into a Network subdirectory in newer versions
+func chromiumCookiesFile(profile string) (string, error) {
+	for _, path := range []string{
+		filepath.Join(profile, "Network", "Cookies"),
+		filepath.Join(profile, "Cookies"),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", os.ErrNotExist
+}
+
+// chromiumProfileDirs lists the default profile directories of a Chromium-based browser on this platform
+func chromiumProfileDirs(browser string) []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	switch runtime.GOOS {
+	case "darwin":
+		base := filepath.Join(home, "Library", "Application Support")
+		dirs = map[string][]string{
+			"chrome":   {filepath.Join(base, "Google", "Chrome")},
+			"chromium": {filepath.Join(base, "Chromium")},
+			"brave":    {filepath.Join(base, "BraveSoftware", "Brave-Browser")},
+			"edge":     {filepath.Join(base, "Microsoft Edge")},
+		}[browser]
+	case "windows":
+		base := os.Getenv("LOCALAPPDATA")
+		dirs = map[string][]string{
+			"chrome":   {filepath.Join(base, "Google", "Chrome", "User Data")},
+			"chromium": {filepath.Join(base, "Chromium", "User Data")},
+			"brave":    {filepath.Join(base, "BraveSoftware", "Brave-Browser", "User Data")},
+			"edge":     {filepath.Join(base, "Microsoft", "Edge", "User Data")},
+		}[browser]
+	default:
+		base := filepath.Join(home, ".config")
+		dirs = map[string][]string{
+			"chrome":   {filepath.Join(base, "google-chrome")},
+			"chromium": {filepath.Join(base, "chromium"), filepath.Join(home, "snap", "chromium", "common", "chromium")},
+			"brave":    {filepath.Join(base, "BraveSoftware", "Brave-Browser")},
+			"edge":     {filepath.Join(base, "microsoft-edge")},
+		}[browser]
+	}
+
+	profiles := make([]string, len(dirs))
+	for i, dir := range dirs {
+		profiles[i] = filepath.Join(dir, "Default")
+	}
+	return profiles
+}
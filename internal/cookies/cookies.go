@@ -0,0 +1,179 @@
+// Package cookies reads Patreon session cookies from browser cookie stores and cookies.txt files
+package cookies
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Domain is the site whose cookies are loaded
+const Domain = "patreon.com"
+
+// ErrNoCookies is returned when a source has no usable cookies for Domain
+var ErrNoCookies = errors.New("no patreon.com cookies found")
+
+// Cookie is a single cookie read from a cookie store
+type Cookie struct {
+	Domain  string
+	Path    string
+	Name    string
+	Value   string
+	Secure  bool
+	Expires time.Time // Zero for session cookies
+}
+
+// Source reads cookies from a browser profile or cookie file
+type Source interface {
+	// Name describes the source for messages, e.g. "Firefox (/home/me/.mozilla/...)"
+	Name() string
+	// Cookies returns the cookies stored for domain and its subdomains
+	Cookies(domain string) ([]Cookie, error)
+}
+
+// ParseSource selects a cookie source from a cookie_source value:
+//
+//	firefox[:<profile dir or cookies.sqlite>]
+//	chrome[:<Cookies file>], chromium[:<Cookies file>]
+//	netscape:<cookies.txt>, or a path to a cookies.txt file
+//
+// Browser sources without a path use the default profile of the current user.
+func ParseSource(spec string) (Source, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, errors.New("empty cookie source")
+	}
+
+	kind, path, _ := strings.Cut(spec, ":")
+	if filepath.VolumeName(spec) != "" {
+		// "C:\..." is a Windows path, not a "c:" source
+		kind, path = "", spec
+	}
+
+	switch strings.ToLower(kind) {
+	case "firefox":
+		return firefoxSource{path: path}, nil
+	case "chrome", "chromium", "brave", "edge":
+		return chromiumSource{browser: strings.ToLower(kind), path: path}, nil
+	case "netscape", "cookies.txt":
+		if path == "" {
+			return nil, fmt.Errorf("cookie source %q needs a file path", spec)
+		}
+		return netscapeSource{path: path}, nil
+	}
+
+	// Anything else is taken as a file, recognised by name
+	switch strings.ToLower(filepath.Base(spec)) {
+	case "cookies.sqlite":
+		return firefoxSource{path: spec}, nil
+	case "cookies":
+		return chromiumSource{browser: "chrome", path: spec}, nil
+	}
+	if _, err := os.Stat(spec); err != nil {
+		return nil, fmt.Errorf("unknown cookie source %q (expected firefox, chrome, chromium or a cookies.txt path)", spec)
+	}
+	return netscapeSource{path: spec}, nil
+}
+
+// Load reads the Patreon cookies from the source described by spec and returns them
+// as a Cookie header value, along with the source they came from
+func Load(spec string) (string, Source, error) {
+	source, err := ParseSource(spec)
+	if err != nil {
+		return "", nil, err
+	}
+	cookies, err := source.Cookies(Domain)
+	if err != nil {
+		return "", source, fmt.Errorf("failed to read cookies from %s: %w", source.Name(), err)
+	}
+	header := Header(Usable(cookies, time.Now()))
+	if header == "" {
+		return "", source, fmt.Errorf("%w in %s", ErrNoCookies, source.Name())
+	}
+	return header, source, nil
+}
+
+// Usable drops expired cookies and keeps only the most specific cookie for each name
+func Usable(cookies []Cookie, now time.Time) []Cookie {
+	var result []Cookie
+	index := make(map[string]int)
+	for _, c := range cookies {
+		if c.Name == "" || (!c.Expires.IsZero() && c.Expires.Before(now)) {
+			continue
+		}
+		if i, ok := index[c.Name]; ok {
+			// Prefer www.patreon.com over .patreon.com, and longer paths over "/"
+			prev := result[i]
+			if len(c.Domain)+len(c.Path) > len(prev.Domain)+len(prev.Path) {
+				result[i] = c
+			}
+			continue
+		}
+		index[c.Name] = len(result)
+		result = append(result, c)
+	}
+	return result
+}
+
+// Header formats cookies as a Cookie header value, e.g. "session_id=abc; patreon_device_id=xyz"
+func Header(cookies []Cookie) string {
+	parts := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		parts = append(parts, c.Name+"="+c.Value)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// MatchesDomain reports whether a cookie stored for host is sent to domain
+func MatchesDomain(host, domain string) bool {
+	host = strings.TrimPrefix(strings.ToLower(host), ".")
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// copyDatabase copies a SQLite database and its write-ahead log into a temporary
+// directory, since browsers keep their cookie databases locked while running.
+// The returned cleanup function removes the copy.
+func copyDatabase(path string) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "patreon-posts-cookies-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	dst := filepath.Join(dir, filepath.Base(path))
+	if err := copyFile(path, dst); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	// Recent changes may still be in the WAL; a missing one just means there are none
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := copyFile(path+suffix, dst+suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			cleanup()
+			return "", nil, err
+		}
+	}
+	return dst, cleanup, nil
+}
+
+// copyFile copies src to dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return out.Close()
+}
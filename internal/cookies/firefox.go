@@ -0,0 +1,126 @@
+package cookies
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// firefoxSource reads the cookies.sqlite database of a Firefox profile
+type firefoxSource struct {
+	path string // Profile directory or cookies.sqlite file; empty for the most recently used profile
+}
+
+func (s firefoxSource) Name() string {
+	if s.path == "" {
+		return "Firefox"
+	}
+	return "Firefox (" + s.path + ")"
+}
+
+func (s firefoxSource) Cookies(domain string) ([]Cookie, error) {
+	path, err := s.database()
+	if err != nil {
+		return nil, err
+	}
+
+	copied, cleanup, err := copyDatabase(path)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	db, err := sql.Open("sqlite", copied)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT host, path, name, value, isSecure, expiry
+		FROM moz_cookies
+		WHERE host = ? OR host LIKE ?
+	`, domain, "%."+domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", path, err)
+	}
+	defer rows.Close()
+
+	var cookies []Cookie
+	for rows.Next() {
+		var c Cookie
+		var expiry int64
+		if err := rows.Scan(&c.Domain, &c.Path, &c.Name, &c.Value, &c.Secure, &expiry); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		c.Expires = firefoxExpiry(expiry)
+		cookies = append(cookies, c)
+	}
+	return cookies, rows.Err()
+}
+
+// firefoxExpiry converts a moz_cookies expiry, which newer Firefox versions store in milliseconds
+func firefoxExpiry(expiry int64) time.Time {
+	switch {
+	case expiry <= 0:
+		return time.Time{}
+	case expiry > 1e11:
+		return time.UnixMilli(expiry)
+	}
+	return time.Unix(expiry, 0)
+}
+
+// database returns the cookies.sqlite file to read
+func (s firefoxSource) database() (string, error) {
+	if s.path != "" {
+		if info, err := os.Stat(s.path); err == nil && info.IsDir() {
+			return filepath.Join(s.path, "cookies.sqlite"), nil
+		}
+		return s.path, nil
+	}
+
+	// Without a profile, use whichever profile's cookies changed last
+	var newest string
+	var newestMod time.Time
+	for _, dir := range firefoxProfileDirs() {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*", "cookies.sqlite"))
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				continue
+			}
+			if newest == "" || info.ModTime().After(newestMod) {
+				newest, newestMod = match, info.ModTime()
+			}
+		}
+	}
+	if newest == "" {
+		return "", errors.New("no Firefox profile found, pass firefox:<profile dir>")
+	}
+	return newest, nil
+}
+
+// firefoxProfileDirs lists the directories Firefox keeps profiles in on this platform
+func firefoxProfileDirs() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{filepath.Join(home, "Library", "Application Support", "Firefox", "Profiles")}
+	case "windows":
+		return []string{filepath.Join(os.Getenv("APPDATA"), "Mozilla", "Firefox", "Profiles")}
+	}
+	return []string{
+		filepath.Join(home, ".mozilla", "firefox"),
+		filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox"),
+		filepath.Join(home, ".var", "app", "org.mozilla.firefox", ".mozilla", "firefox"),
+	}
+}
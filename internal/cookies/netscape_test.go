@@ -0,0 +1,66 @@
+package cookies
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNetscapeSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    []Cookie
+		wantErr string
+	}{
+		{
+			name: "cookies for the domain",
+			file: "# Netscape HTTP Cookie File\n" +
+				"\n" +
+				".patreon.com\tTRUE\t/\tTRUE\t1700000000\tsession_id\tabc\r\n" +
+				"#HttpOnly_www.patreon.com\tFALSE\t/api\tFALSE\t0\tdevice\tx\ty\n" +
+				".example.com\tTRUE\t/\tFALSE\t0\tother\tz\n" +
+				"notpatreon.com\tTRUE\t/\tFALSE\t0\tother\tz\n",
+			want: []Cookie{
+				{Domain: ".patreon.com", Path: "/", Name: "session_id", Value: "abc", Secure: true, Expires: time.Unix(1700000000, 0)},
+				{Domain: "www.patreon.com", Path: "/api", Name: "device", Value: "x\ty"},
+			},
+		},
+		{
+			name:    "too few fields",
+			file:    "# comment\n.patreon.com TRUE / TRUE 0 session_id abc\n",
+			wantErr: "line 2: expected 7 tab-separated fields, got 1",
+		},
+		{
+			name:    "invalid expiry",
+			file:    ".patreon.com\tTRUE\t/\tTRUE\tnever\tsession_id\tabc\n",
+			wantErr: `line 1: invalid expiry "never"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cookies.txt")
+			if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := netscapeSource{path: path}.Cookies("patreon.com")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Cookies() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
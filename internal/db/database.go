@@ -1,13 +1,23 @@
 package db
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
+
+	"patreon-posts/internal/models"
 )
 
 // Database handles SQLite operations
@@ -33,26 +43,41 @@ type CachedPost struct {
 	CurrentUserCanView bool
 	PublishedAt        time.Time
 	Description        string
+	Content            string // Raw HTML content
 	YouTubeLinks       string // JSON array of links
+	ProviderLinks      string // JSON array of models.ProviderLink
+	Links              string // JSON array of models.Link
+	Media              string // JSON array of models.MediaFile
 	CachedAt           time.Time
 	DetailsCached      bool
 }
 
-// DefaultDBPath returns the default database path
-func DefaultDBPath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+// Open opens or creates the database. A database other users can read is made private
+// first, and refused with ErrInsecureDatabase if that isn't possible.
+func Open(path string) (*Database, error) {
+	// The XDG data directory may not exist yet
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+	// Session cookies are stored in the database, so it's created readable only by its
+	// owner; SQLite gives its journal files the same permissions. An existing one is checked.
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if err == nil {
+		f.Close()
+	} else if !errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("failed to create database: %w", err)
+	} else if err := makePrivate(path); err != nil {
+		return nil, err
 	}
-	return filepath.Join(home, ".patreon-posts.db"), nil
-}
 
-// Open opens or creates the database
-func Open(path string) (*Database, error) {
-	db, err := sql.Open("sqlite", path)
+	// The TUI, config reloads and cookie updates write from different goroutines. A single
+	// connection serializes them, and the busy timeout waits out other processes' writes
+	// rather than failing with SQLITE_BUSY.
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	db.SetMaxOpenConns(1)
 
 	d := &Database{db: db}
 	if err := d.migrate(); err != nil {
@@ -63,6 +88,28 @@ func Open(path string) (*Database, error) {
 	return d, nil
 }
 
+// ErrInsecureDatabase is returned by Open when the group or other users can access the
+// database, which holds session cookies, and its permissions can't be changed
+var ErrInsecureDatabase = errors.New("database holds session cookies and is readable by other users")
+
+// makePrivate removes other users' access to an existing database and its journal files,
+// such as a database created by an earlier version with the default permissions
+func makePrivate(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	for _, suffix := range []string{"", "-wal", "-shm", "-journal"} {
+		info, err := os.Stat(path + suffix)
+		if err != nil || info.Mode().Perm()&0o077 == 0 {
+			continue
+		}
+		if err := os.Chmod(path+suffix, info.Mode().Perm()&^0o077); err != nil {
+			return fmt.Errorf("%w: run chmod 600 %s", ErrInsecureDatabase, path+suffix)
+		}
+	}
+	return nil
+}
+
 // Close closes the database
 func (d *Database) Close() error {
 	return d.db.Close()
@@ -86,7 +133,11 @@ func (d *Database) migrate() error {
 		current_user_can_view BOOLEAN,
 		published_at DATETIME,
 		description TEXT,
+		content TEXT,
 		youtube_links TEXT,
+		provider_links TEXT,
+		links TEXT,
+		media TEXT,
 		cached_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		details_cached BOOLEAN DEFAULT FALSE,
 		FOREIGN KEY (campaign_id) REFERENCES campaigns(id)
@@ -104,15 +155,113 @@ func (d *Database) migrate() error {
 		PRIMARY KEY (campaign_id, cursor),
 		FOREIGN KEY (campaign_id) REFERENCES campaigns(id)
 	);
+
+	CREATE TABLE IF NOT EXISTS downloads (
+		media_id TEXT PRIMARY KEY,
+		post_id TEXT NOT NULL,
+		campaign_id TEXT,
+		path TEXT NOT NULL,
+		size_bytes INTEGER,
+		downloaded_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_downloads_post ON downloads(post_id);
+
+	CREATE TABLE IF NOT EXISTS comments (
+		id TEXT PRIMARY KEY,
+		post_id TEXT NOT NULL,
+		parent_id TEXT,
+		position INTEGER NOT NULL,
+		author TEXT,
+		body TEXT,
+		created_at DATETIME,
+		is_by_creator BOOLEAN,
+		vote_sum INTEGER,
+		reply_count INTEGER,
+		links TEXT,
+		cached_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_comments_post ON comments(post_id);
+
+	CREATE TABLE IF NOT EXISTS comment_pages (
+		post_id TEXT PRIMARY KEY,
+		next_cursor TEXT,
+		has_more BOOLEAN,
+		cached_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS session_cookies (
+		seed_hash TEXT NOT NULL,
+		name TEXT NOT NULL,
+		domain TEXT NOT NULL,
+		path TEXT NOT NULL,
+		value TEXT,
+		expires DATETIME,
+		removed BOOLEAN DEFAULT FALSE,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (seed_hash, name, domain, path)
+	);
 	`
 
 	_, err := d.db.Exec(schema)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
+
+	// Columns added after the initial schema, for databases created by older versions
+	columns := []struct{ table, column, definition string }{
+		{"posts", "provider_links", "TEXT"},
+		{"posts", "content", "TEXT"},
+		{"posts", "links", "TEXT"},
+		{"posts", "media", "TEXT"},
+		{"campaigns", "membership_status", "TEXT"},
+		{"campaigns", "tier", "TEXT"},
+		{"campaigns", "pledge_cents", "INTEGER"},
+		{"campaigns", "membership_synced_at", "DATETIME"},
+		{"campaigns", "in_config", "BOOLEAN DEFAULT FALSE"},
+	}
+	for _, c := range columns {
+		if err := d.addColumn(c.table, c.column, c.definition); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+	}
 	return nil
 }
 
+// addColumn adds a column to a table unless it already exists
+func (d *Database) addColumn(table, column, definition string) error {
+	rows, err := d.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   bool
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = d.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
 // SaveCampaign saves or updates a campaign
 func (d *Database) SaveCampaign(id, name string) error {
 	_, err := d.db.Exec(`
@@ -127,15 +276,41 @@ func (d *Database) SaveCampaign(id, name string) error {
 
 // SavedCampaign represents a saved campaign for selection
 type SavedCampaign struct {
-	ID       string
-	Name     string
-	CachedAt time.Time
+	ID                 string
+	Name               string
+	CachedAt           time.Time
+	MembershipStatus   string     // Patron status from the last membership sync, see models.PatronStatusActive
+	Tier               string     // Entitled tier title from the last membership sync
+	PledgeCents        int        // Pledge amount from the last membership sync
+	MembershipSyncedAt *time.Time // When the membership was last seen, nil if never synced
+}
+
+// Lapsed reports whether the last membership sync found the pledge declined, cancelled or gone
+func (c SavedCampaign) Lapsed() bool {
+	return c.MembershipStatus == models.PatronStatusDeclined || c.MembershipStatus == models.PatronStatusFormer
+}
+
+// campaignColumns are the columns scanned by scanCampaign, in order
+const campaignColumns = `id, COALESCE(name, ''), cached_at, COALESCE(membership_status, ''),
+		COALESCE(tier, ''), COALESCE(pledge_cents, 0), membership_synced_at`
+
+// scanCampaign reads a row selected with campaignColumns
+func scanCampaign(row interface{ Scan(...any) error }) (SavedCampaign, error) {
+	var c SavedCampaign
+	var syncedAt sql.NullTime
+	if err := row.Scan(&c.ID, &c.Name, &c.CachedAt, &c.MembershipStatus, &c.Tier, &c.PledgeCents, &syncedAt); err != nil {
+		return c, err
+	}
+	if syncedAt.Valid {
+		c.MembershipSyncedAt = &syncedAt.Time
+	}
+	return c, nil
 }
 
 // ListCampaigns returns all saved campaigns
 func (d *Database) ListCampaigns() ([]SavedCampaign, error) {
 	rows, err := d.db.Query(`
-		SELECT id, COALESCE(name, ''), cached_at
+		SELECT ` + campaignColumns + `
 		FROM campaigns
 		ORDER BY cached_at DESC
 	`)
@@ -146,8 +321,8 @@ func (d *Database) ListCampaigns() ([]SavedCampaign, error) {
 
 	var campaigns []SavedCampaign
 	for rows.Next() {
-		var c SavedCampaign
-		if err := rows.Scan(&c.ID, &c.Name, &c.CachedAt); err != nil {
+		c, err := scanCampaign(rows)
+		if err != nil {
 			return nil, err
 		}
 		campaigns = append(campaigns, c)
@@ -158,12 +333,11 @@ func (d *Database) ListCampaigns() ([]SavedCampaign, error) {
 // GetCampaign retrieves a campaign by ID
 func (d *Database) GetCampaign(id string) (*SavedCampaign, error) {
 	row := d.db.QueryRow(`
-		SELECT id, COALESCE(name, ''), cached_at
+		SELECT `+campaignColumns+`
 		FROM campaigns WHERE id = ?
 	`, id)
 
-	var c SavedCampaign
-	err := row.Scan(&c.ID, &c.Name, &c.CachedAt)
+	c, err := scanCampaign(row)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -173,21 +347,223 @@ func (d *Database) GetCampaign(id string) (*SavedCampaign, error) {
 	return &c, nil
 }
 
+// MembershipSync summarizes the changes made by SyncMemberships
+type MembershipSync struct {
+	Added  []string // Campaigns that weren't saved before
+	Lapsed []string // Campaigns whose pledge has been declined, cancelled or dropped since the last sync
+	Total  int      // Memberships saved
+}
+
+// SyncMemberships saves the campaigns in the user's memberships with their tier and pledge
+// status, and flags previously synced campaigns missing from them as former patronages.
+// Existing campaigns keep their position in the list.
+func (d *Database) SyncMemberships(memberships []models.Membership) (*MembershipSync, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync memberships: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &MembershipSync{}
+	current := make(map[string]bool, len(memberships))
+	for _, m := range memberships {
+		var previous string
+		err := tx.QueryRow(`SELECT COALESCE(membership_status, '') FROM campaigns WHERE id = ?`, m.Campaign.ID).Scan(&previous)
+		switch {
+		case err == sql.ErrNoRows:
+			result.Added = append(result.Added, m.Campaign.ID)
+		case err != nil:
+			return nil, fmt.Errorf("failed to sync memberships: %w", err)
+		case m.Lapsed() && !(SavedCampaign{MembershipStatus: previous}).Lapsed():
+			result.Lapsed = append(result.Lapsed, m.Campaign.ID)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO campaigns (id, name, cached_at, membership_status, tier, pledge_cents, membership_synced_at)
+			VALUES (?, ?, CURRENT_TIMESTAMP, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(id) DO UPDATE SET
+				name = CASE WHEN excluded.name != '' THEN excluded.name ELSE campaigns.name END,
+				membership_status = excluded.membership_status,
+				tier = excluded.tier,
+				pledge_cents = excluded.pledge_cents,
+				membership_synced_at = CURRENT_TIMESTAMP
+		`, m.Campaign.ID, m.Campaign.Name, m.Status, m.Tier, m.PledgeCents)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save membership: %w", err)
+		}
+		current[m.Campaign.ID] = true
+		result.Total++
+	}
+
+	// Campaigns dropped from the memberships entirely are flagged as former patronages
+	var missing []string
+	rows, err := tx.Query(`
+		SELECT id FROM campaigns
+		WHERE membership_synced_at IS NOT NULL AND COALESCE(membership_status, '') != ?
+	`, models.PatronStatusFormer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync memberships: %w", err)
+	}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to sync memberships: %w", err)
+		}
+		if !current[id] {
+			missing = append(missing, id)
+			result.Lapsed = append(result.Lapsed, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to sync memberships: %w", err)
+	}
+
+	for _, id := range missing {
+		if _, err := tx.Exec(`
+			UPDATE campaigns SET membership_status = ?, pledge_cents = 0 WHERE id = ?
+		`, models.PatronStatusFormer, id); err != nil {
+			return nil, fmt.Errorf("failed to flag lapsed membership: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to sync memberships: %w", err)
+	}
+	return result, nil
+}
+
 // DeleteCampaign removes a campaign and all its data
 func (d *Database) DeleteCampaign(id string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteCampaign(tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// deleteCampaign removes a campaign along with its cached pages, posts, comments and download records
+func deleteCampaign(tx *sql.Tx, id string) error {
 	// Delete pages first
-	if _, err := d.db.Exec(`DELETE FROM campaign_pages WHERE campaign_id = ?`, id); err != nil {
+	if _, err := tx.Exec(`DELETE FROM campaign_pages WHERE campaign_id = ?`, id); err != nil {
+		return err
+	}
+	// Forget the campaign's downloads, so a re-added campaign's files are checked again
+	if _, err := tx.Exec(`DELETE FROM downloads WHERE campaign_id = ? OR post_id IN (SELECT id FROM posts WHERE campaign_id = ?)`, id, id); err != nil {
+		return err
+	}
+	// Delete comments of the campaign's posts, while the posts still say which they are
+	if _, err := tx.Exec(`DELETE FROM comments WHERE post_id IN (SELECT id FROM posts WHERE campaign_id = ?)`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM comment_pages WHERE post_id IN (SELECT id FROM posts WHERE campaign_id = ?)`, id); err != nil {
 		return err
 	}
 	// Delete posts
-	if _, err := d.db.Exec(`DELETE FROM posts WHERE campaign_id = ?`, id); err != nil {
+	if _, err := tx.Exec(`DELETE FROM posts WHERE campaign_id = ?`, id); err != nil {
 		return err
 	}
 	// Delete campaign
-	_, err := d.db.Exec(`DELETE FROM campaigns WHERE id = ?`, id)
+	_, err := tx.Exec(`DELETE FROM campaigns WHERE id = ?`, id)
 	return err
 }
 
+// CampaignEntry is a campaign listed in the config file
+type CampaignEntry struct {
+	ID   string
+	Name string // Empty keeps the saved name
+}
+
+// CampaignReconcile is the result of ReconcileCampaigns
+type CampaignReconcile struct {
+	Added   []string // IDs listed in the config that weren't saved yet
+	Removed []string // IDs deleted because they left the config or are in deleted_campaigns
+}
+
+// ReconcileCampaigns makes the saved campaigns follow the config file's campaign list,
+// which wins over the database: listed campaigns are saved and take the config's name,
+// campaigns that were listed before but no longer are and campaigns in deleted are removed
+// with their cached posts. Campaigns the config never listed, such as lapsed memberships,
+// are left alone.
+func (d *Database) ReconcileCampaigns(campaigns []CampaignEntry, deleted []string) (*CampaignReconcile, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile campaigns: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &CampaignReconcile{}
+	listed := make(map[string]bool, len(campaigns))
+	for _, c := range campaigns {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM campaigns WHERE id = ?)`, c.ID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to reconcile campaigns: %w", err)
+		}
+		if !exists {
+			result.Added = append(result.Added, c.ID)
+		}
+		_, err := tx.Exec(`
+			INSERT INTO campaigns (id, name, cached_at, in_config)
+			VALUES (?, ?, CURRENT_TIMESTAMP, TRUE)
+			ON CONFLICT(id) DO UPDATE SET
+				name = CASE WHEN excluded.name != '' THEN excluded.name ELSE campaigns.name END,
+				in_config = TRUE
+		`, c.ID, c.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save campaign: %w", err)
+		}
+		listed[c.ID] = true
+	}
+
+	// Campaigns the config listed before, but has since dropped
+	rows, err := tx.Query(`SELECT id FROM campaigns WHERE in_config`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile campaigns: %w", err)
+	}
+	var remove []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to reconcile campaigns: %w", err)
+		}
+		if !listed[id] {
+			remove = append(remove, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to reconcile campaigns: %w", err)
+	}
+
+	for _, id := range deleted {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM campaigns WHERE id = ?)`, id).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to reconcile campaigns: %w", err)
+		}
+		if exists && !listed[id] && !slices.Contains(remove, id) {
+			remove = append(remove, id)
+		}
+	}
+	for _, id := range remove {
+		if err := deleteCampaign(tx, id); err != nil {
+			return nil, fmt.Errorf("failed to delete campaign %s: %w", id, err)
+		}
+	}
+	result.Removed = remove
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to reconcile campaigns: %w", err)
+	}
+	return result, nil
+}
+
 // SavePost saves or updates a post (basic info from list)
 func (d *Database) SavePost(post *CachedPost) error {
 	_, err := d.db.Exec(`
@@ -208,34 +584,86 @@ func (d *Database) SavePost(post *CachedPost) error {
 }
 
 // SavePostDetails saves the detailed content of a post
-func (d *Database) SavePostDetails(postID, description, youtubeLinks string) error {
-	_, err := d.db.Exec(`
+func (d *Database) SavePostDetails(details *models.PostDetails) error {
+	youtubeLinks, err := json.Marshal(details.YouTubeLinks)
+	if err != nil {
+		return fmt.Errorf("failed to encode youtube links: %w", err)
+	}
+	providerLinks, err := json.Marshal(details.ProviderLinks)
+	if err != nil {
+		return fmt.Errorf("failed to encode provider links: %w", err)
+	}
+	postLinks, err := json.Marshal(details.Links)
+	if err != nil {
+		return fmt.Errorf("failed to encode links: %w", err)
+	}
+	media, err := json.Marshal(details.Media)
+	if err != nil {
+		return fmt.Errorf("failed to encode media: %w", err)
+	}
+
+	_, err = d.db.Exec(`
 		UPDATE posts SET 
 			description = ?,
+			content = ?,
 			youtube_links = ?,
+			provider_links = ?,
+			links = ?,
+			media = ?,
 			details_cached = TRUE
 		WHERE id = ?
-	`, description, youtubeLinks, postID)
+	`, details.Description, details.Content, string(youtubeLinks), string(providerLinks),
+		string(postLinks), string(media), details.ID)
 	return err
 }
 
+// Details converts the cached post back into post details, decoding the stored link lists
+func (p *CachedPost) Details() *models.PostDetails {
+	details := &models.PostDetails{
+		ID:          p.ID,
+		Title:       p.Title,
+		Description: p.Description,
+		Content:     p.Content,
+		PostType:    p.PostType,
+		PublishedAt: p.PublishedAt,
+	}
+	if p.YouTubeLinks != "" {
+		json.Unmarshal([]byte(p.YouTubeLinks), &details.YouTubeLinks)
+	}
+	if p.Links != "" {
+		json.Unmarshal([]byte(p.Links), &details.Links)
+	}
+	if p.Media != "" {
+		json.Unmarshal([]byte(p.Media), &details.Media)
+	}
+	if p.ProviderLinks != "" {
+		json.Unmarshal([]byte(p.ProviderLinks), &details.ProviderLinks)
+	} else {
+		// Cached by a version without provider links; YouTube was the only provider then
+		for _, url := range details.YouTubeLinks {
+			details.ProviderLinks = append(details.ProviderLinks, models.ProviderLink{Provider: "youtube", URL: url})
+		}
+	}
+	return details
+}
+
 // GetPost retrieves a cached post by ID
 func (d *Database) GetPost(postID string) (*CachedPost, error) {
 	row := d.db.QueryRow(`
 		SELECT id, campaign_id, type, post_type, title, patreon_url,
-			current_user_can_view, published_at, description, youtube_links,
-			cached_at, details_cached
+			current_user_can_view, published_at, description, content, youtube_links,
+			provider_links, links, media, cached_at, details_cached
 		FROM posts WHERE id = ?
 	`, postID)
 
 	var post CachedPost
-	var desc, links sql.NullString
+	var desc, content, links, providerLinks, postLinks, media sql.NullString
 	var publishedAt sql.NullTime
 
 	err := row.Scan(
 		&post.ID, &post.CampaignID, &post.Type, &post.PostType,
 		&post.Title, &post.PatreonURL, &post.CurrentUserCanView,
-		&publishedAt, &desc, &links, &post.CachedAt, &post.DetailsCached,
+		&publishedAt, &desc, &content, &links, &providerLinks, &postLinks, &media, &post.CachedAt, &post.DetailsCached,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -250,9 +678,21 @@ func (d *Database) GetPost(postID string) (*CachedPost, error) {
 	if desc.Valid {
 		post.Description = desc.String
 	}
+	if content.Valid {
+		post.Content = content.String
+	}
 	if links.Valid {
 		post.YouTubeLinks = links.String
 	}
+	if providerLinks.Valid {
+		post.ProviderLinks = providerLinks.String
+	}
+	if postLinks.Valid {
+		post.Links = postLinks.String
+	}
+	if media.Valid {
+		post.Media = media.String
+	}
 
 	return &post, nil
 }
@@ -261,8 +701,8 @@ func (d *Database) GetPost(postID string) (*CachedPost, error) {
 func (d *Database) GetPostsByCampaign(campaignID string) ([]CachedPost, error) {
 	rows, err := d.db.Query(`
 		SELECT id, campaign_id, type, post_type, title, patreon_url,
-			current_user_can_view, published_at, description, youtube_links,
-			cached_at, details_cached
+			current_user_can_view, published_at, description, content, youtube_links,
+			provider_links, links, media, cached_at, details_cached
 		FROM posts WHERE campaign_id = ?
 		ORDER BY published_at DESC
 	`, campaignID)
@@ -274,13 +714,13 @@ func (d *Database) GetPostsByCampaign(campaignID string) ([]CachedPost, error) {
 	var posts []CachedPost
 	for rows.Next() {
 		var post CachedPost
-		var desc, links sql.NullString
+		var desc, content, links, providerLinks, postLinks, media sql.NullString
 		var publishedAt sql.NullTime
 
 		err := rows.Scan(
 			&post.ID, &post.CampaignID, &post.Type, &post.PostType,
 			&post.Title, &post.PatreonURL, &post.CurrentUserCanView,
-			&publishedAt, &desc, &links, &post.CachedAt, &post.DetailsCached,
+			&publishedAt, &desc, &content, &links, &providerLinks, &postLinks, &media, &post.CachedAt, &post.DetailsCached,
 		)
 		if err != nil {
 			return nil, err
@@ -292,9 +732,21 @@ func (d *Database) GetPostsByCampaign(campaignID string) ([]CachedPost, error) {
 		if desc.Valid {
 			post.Description = desc.String
 		}
+		if content.Valid {
+			post.Content = content.String
+		}
 		if links.Valid {
 			post.YouTubeLinks = links.String
 		}
+		if providerLinks.Valid {
+			post.ProviderLinks = providerLinks.String
+		}
+		if postLinks.Valid {
+			post.Links = postLinks.String
+		}
+		if media.Valid {
+			post.Media = media.String
+		}
 
 		posts = append(posts, post)
 	}
@@ -329,7 +781,11 @@ func (d *Database) ClearPostDetails(postID string) error {
 	_, err := d.db.Exec(`
 		UPDATE posts SET 
 			description = NULL,
+			content = NULL,
 			youtube_links = NULL,
+			provider_links = NULL,
+			links = NULL,
+			media = NULL,
 			details_cached = FALSE
 		WHERE id = ?
 	`, postID)
@@ -397,3 +853,277 @@ func (d *Database) ClearPage(campaignID, cursor string) error {
 	_, err := d.db.Exec(`DELETE FROM campaign_pages WHERE campaign_id = ? AND cursor = ?`, campaignID, cursor)
 	return err
 }
+
+// Download records a media file that has been fully downloaded
+type Download struct {
+	MediaID      string
+	PostID       string
+	CampaignID   string
+	Path         string
+	SizeBytes    int64
+	DownloadedAt time.Time
+}
+
+// SaveDownload records a completed download, replacing any earlier record for the same file
+func (d *Database) SaveDownload(download *Download) error {
+	_, err := d.db.Exec(`
+		INSERT INTO downloads (media_id, post_id, campaign_id, path, size_bytes, downloaded_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(media_id) DO UPDATE SET
+			post_id = excluded.post_id,
+			campaign_id = excluded.campaign_id,
+			path = excluded.path,
+			size_bytes = excluded.size_bytes,
+			downloaded_at = CURRENT_TIMESTAMP
+	`, download.MediaID, download.PostID, download.CampaignID, download.Path, download.SizeBytes)
+	return err
+}
+
+// GetDownload retrieves the download record for a media file, or nil if it hasn't been downloaded
+func (d *Database) GetDownload(mediaID string) (*Download, error) {
+	row := d.db.QueryRow(`
+		SELECT media_id, post_id, campaign_id, path, size_bytes, downloaded_at
+		FROM downloads WHERE media_id = ?
+	`, mediaID)
+
+	var download Download
+	var campaignID sql.NullString
+	err := row.Scan(&download.MediaID, &download.PostID, &campaignID, &download.Path,
+		&download.SizeBytes, &download.DownloadedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if campaignID.Valid {
+		download.CampaignID = campaignID.String
+	}
+	return &download, nil
+}
+
+// DeleteDownload forgets a download record, e.g. when the file has gone missing from disk
+func (d *Database) DeleteDownload(mediaID string) error {
+	_, err := d.db.Exec(`DELETE FROM downloads WHERE media_id = ?`, mediaID)
+	return err
+}
+
+// SaveComments appends a page of comments and their replies to a post's cached comments
+// and records the cursor for the next page
+func (d *Database) SaveComments(postID string, page *models.CommentsPage) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save comments: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Keep fetch order across pages
+	var position int
+	if err := tx.QueryRow(`
+		SELECT COALESCE(MAX(position), -1) + 1 FROM comments WHERE post_id = ?
+	`, postID).Scan(&position); err != nil {
+		return fmt.Errorf("failed to save comments: %w", err)
+	}
+
+	var save func(comments []models.Comment) error
+	save = func(comments []models.Comment) error {
+		for _, comment := range comments {
+			links, err := json.Marshal(comment.Links)
+			if err != nil {
+				return fmt.Errorf("failed to encode comment links: %w", err)
+			}
+			if _, err := tx.Exec(`
+				INSERT INTO comments (id, post_id, parent_id, position, author, body, created_at,
+					is_by_creator, vote_sum, reply_count, links, cached_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+				ON CONFLICT(id) DO UPDATE SET
+					author = excluded.author,
+					body = excluded.body,
+					vote_sum = excluded.vote_sum,
+					reply_count = excluded.reply_count,
+					links = excluded.links,
+					cached_at = CURRENT_TIMESTAMP
+			`, comment.ID, postID, comment.ParentID, position, comment.Author, comment.Body, comment.CreatedAt,
+				comment.IsByCreator, comment.VoteSum, comment.ReplyCount, string(links)); err != nil {
+				return fmt.Errorf("failed to save comment %s: %w", comment.ID, err)
+			}
+			position++
+			if err := save(comment.Replies); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := save(page.Comments); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO comment_pages (post_id, next_cursor, has_more, cached_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(post_id) DO UPDATE SET
+			next_cursor = excluded.next_cursor,
+			has_more = excluded.has_more,
+			cached_at = CURRENT_TIMESTAMP
+	`, postID, page.NextCursor, page.HasMore); err != nil {
+		return fmt.Errorf("failed to save comments: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetComments retrieves a post's cached comments as threads, or nil if none have been fetched.
+// NextCursor and HasMore describe the page after the last one cached.
+func (d *Database) GetComments(postID string) (*models.CommentsPage, error) {
+	page := &models.CommentsPage{}
+	var nextCursor sql.NullString
+	err := d.db.QueryRow(`
+		SELECT next_cursor, has_more FROM comment_pages WHERE post_id = ?
+	`, postID).Scan(&nextCursor, &page.HasMore)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if nextCursor.Valid {
+		page.NextCursor = nextCursor.String
+	}
+
+	rows, err := d.db.Query(`
+		SELECT id, COALESCE(parent_id, ''), COALESCE(author, ''), COALESCE(body, ''), created_at,
+			is_by_creator, vote_sum, reply_count, links
+		FROM comments WHERE post_id = ?
+		ORDER BY position
+	`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var flat []models.Comment
+	for rows.Next() {
+		comment := models.Comment{PostID: postID}
+		var createdAt sql.NullTime
+		var links sql.NullString
+		if err := rows.Scan(&comment.ID, &comment.ParentID, &comment.Author, &comment.Body, &createdAt,
+			&comment.IsByCreator, &comment.VoteSum, &comment.ReplyCount, &links); err != nil {
+			return nil, err
+		}
+		if createdAt.Valid {
+			comment.CreatedAt = createdAt.Time
+		}
+		if links.Valid {
+			json.Unmarshal([]byte(links.String), &comment.Links)
+		}
+		flat = append(flat, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page.Comments = threadComments(flat, "")
+	return page, nil
+}
+
+// threadComments nests comments under their parents, keeping the stored order
+func threadComments(flat []models.Comment, parentID string) []models.Comment {
+	var thread []models.Comment
+	for _, comment := range flat {
+		if comment.ParentID == parentID {
+			comment.Replies = threadComments(flat, comment.ID)
+			thread = append(thread, comment)
+		}
+	}
+	return thread
+}
+
+// ClearComments removes a post's cached comments so they are fetched again
+func (d *Database) ClearComments(postID string) error {
+	if _, err := d.db.Exec(`DELETE FROM comments WHERE post_id = ?`, postID); err != nil {
+		return err
+	}
+	_, err := d.db.Exec(`DELETE FROM comment_pages WHERE post_id = ?`, postID)
+	return err
+}
+
+// seedHash identifies the configured Cookie header saved cookies belong to, without storing it
+func seedHash(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadCookies returns the unexpired cookies Patreon updated during sessions that started
+// from the seed Cookie header; cookies it removed have MaxAge < 0. Changing the configured
+// cookies starts over.
+func (d *Database) LoadCookies(seed string) ([]*http.Cookie, error) {
+	rows, err := d.db.Query(`
+		SELECT name, domain, path, COALESCE(value, ''), expires, removed
+		FROM session_cookies
+		WHERE seed_hash = ? AND (expires IS NULL OR expires > ?)
+		ORDER BY updated_at
+	`, seedHash(seed), time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cookies: %w", err)
+	}
+	defer rows.Close()
+
+	var cookies []*http.Cookie
+	for rows.Next() {
+		c := &http.Cookie{}
+		var expires sql.NullTime
+		var removed bool
+		if err := rows.Scan(&c.Name, &c.Domain, &c.Path, &c.Value, &expires, &removed); err != nil {
+			return nil, fmt.Errorf("failed to load cookies: %w", err)
+		}
+		if expires.Valid {
+			c.Expires = expires.Time
+		}
+		if removed {
+			c.MaxAge = -1
+		}
+		cookies = append(cookies, c)
+	}
+	return cookies, rows.Err()
+}
+
+// SaveCookies records cookies Patreon updated for the session that started from the seed
+// Cookie header. Cookies with MaxAge < 0 are remembered as removed, so they stay removed
+// even though the seed still contains them. Cookies saved for other seeds are dropped.
+func (d *Database) SaveCookies(seed string, cookies []*http.Cookie) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save cookies: %w", err)
+	}
+	defer tx.Rollback()
+
+	hash := seedHash(seed)
+	if _, err := tx.Exec(`DELETE FROM session_cookies WHERE seed_hash != ?`, hash); err != nil {
+		return fmt.Errorf("failed to save cookies: %w", err)
+	}
+
+	for _, c := range cookies {
+		removed := c.MaxAge < 0
+		var expires any
+		if !c.Expires.IsZero() && !removed {
+			expires = c.Expires.UTC()
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO session_cookies (seed_hash, name, domain, path, value, expires, removed, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(seed_hash, name, domain, path) DO UPDATE SET
+				value = excluded.value,
+				expires = excluded.expires,
+				removed = excluded.removed,
+				updated_at = CURRENT_TIMESTAMP
+		`, hash, c.Name, strings.TrimPrefix(c.Domain, "."), c.Path, c.Value, expires, removed); err != nil {
+			return fmt.Errorf("failed to save cookies: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save cookies: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package db
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"patreon-posts/internal/models"
+)
+
+// openTest opens a new database in a temporary directory
+func openTest(t *testing.T) *Database {
+	t.Helper()
+	d, err := Open(filepath.Join(t.TempDir(), "patreon-posts.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+// campaignIDs returns the IDs of the saved campaigns in sorted order
+func campaignIDs(t *testing.T, d *Database) []string {
+	t.Helper()
+	campaigns, err := d.ListCampaigns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, c := range campaigns {
+		ids = append(ids, c.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestOpenMakesDatabasePrivate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patreon-posts.db")
+	d, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Close()
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("new database mode = %o, want 600", info.Mode().Perm())
+	}
+
+	// Group access is removed as well as world access
+	if err := os.WriteFile(path+"-journal", nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, suffix := range []string{"", "-journal"} {
+		if err := os.Chmod(path+suffix, 0o640); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Close()
+	for _, suffix := range []string{"", "-journal"} {
+		if info, err := os.Stat(path + suffix); err != nil || info.Mode().Perm() != 0o600 {
+			t.Errorf("%s mode = %o, want 600", filepath.Base(path+suffix), info.Mode().Perm())
+		}
+	}
+}
+
+func TestCookies(t *testing.T) {
+	d := openTest(t)
+	const seed = "session_id=abc"
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	err := d.SaveCookies(seed, []*http.Cookie{
+		{Name: "session_id", Value: "rotated", Domain: ".patreon.com", Path: "/", Expires: expires},
+		{Name: "old", Domain: "patreon.com", Path: "/", MaxAge: -1},
+		{Name: "stale", Value: "x", Domain: "patreon.com", Path: "/", Expires: time.Now().Add(-time.Hour)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A later update replaces the earlier value
+	if err := d.SaveCookies(seed, []*http.Cookie{{Name: "session_id", Value: "rotated again", Domain: "patreon.com", Path: "/", Expires: expires}}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := d.LoadCookies(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*http.Cookie{
+		{Name: "old", Domain: "patreon.com", Path: "/", MaxAge: -1},
+		{Name: "session_id", Value: "rotated again", Domain: "patreon.com", Path: "/", Expires: expires},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("LoadCookies() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].Value != want[i].Value || got[i].Domain != want[i].Domain ||
+			got[i].Path != want[i].Path || got[i].MaxAge != want[i].MaxAge || !got[i].Expires.Equal(want[i].Expires) {
+			t.Errorf("cookie %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// Changing the configured cookies starts over
+	if err := d.SaveCookies("session_id=new", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := d.LoadCookies(seed); err != nil || len(got) != 0 {
+		t.Errorf("LoadCookies() with the old seed = %v, %v, want none", got, err)
+	}
+}
+
+func TestReconcileCampaigns(t *testing.T) {
+	d := openTest(t)
+	// A campaign saved outside the config, such as by a membership sync
+	if err := d.SaveCampaign("9", "Member"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := d.ReconcileCampaigns([]CampaignEntry{{ID: "1", Name: "One"}, {ID: "2", Name: "Two"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Added, []string{"1", "2"}) || len(result.Removed) != 0 {
+		t.Errorf("first reconcile = %+v, want 1 and 2 added", result)
+	}
+	if err := d.SavePost(&CachedPost{ID: "p2", CampaignID: "2", Title: "post"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Campaign 2 leaves the config, and 1 is renamed
+	result, err = d.ReconcileCampaigns([]CampaignEntry{{ID: "1", Name: "Uno"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Added) != 0 || !reflect.DeepEqual(result.Removed, []string{"2"}) {
+		t.Errorf("second reconcile = %+v, want 2 removed", result)
+	}
+	if ids := campaignIDs(t, d); !reflect.DeepEqual(ids, []string{"1", "9"}) {
+		t.Errorf("campaigns = %v, want the never-listed campaign kept", ids)
+	}
+	if c, _ := d.GetCampaign("1"); c == nil || c.Name != "Uno" {
+		t.Errorf("campaign 1 = %+v, want the config's name", c)
+	}
+	if post, err := d.GetPost("p2"); err != nil || post != nil {
+		t.Errorf("post of a removed campaign = %+v, %v, want it deleted", post, err)
+	}
+
+	// Tombstoned campaigns are removed even if the config never listed them
+	result, err = d.ReconcileCampaigns([]CampaignEntry{{ID: "1"}}, []string{"9", "404"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Removed, []string{"9"}) {
+		t.Errorf("third reconcile = %+v, want 9 removed", result)
+	}
+	if ids := campaignIDs(t, d); !reflect.DeepEqual(ids, []string{"1"}) {
+		t.Errorf("campaigns = %v, want only 1", ids)
+	}
+	if c, _ := d.GetCampaign("1"); c == nil || c.Name != "Uno" {
+		t.Errorf("campaign 1 = %+v, want an empty config name to keep the saved one", c)
+	}
+}
+
+func TestSyncMemberships(t *testing.T) {
+	d := openTest(t)
+	if err := d.SaveCampaign("3", "Saved by hand"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := d.SyncMemberships([]models.Membership{
+		{Campaign: models.Campaign{ID: "1", Name: "One"}, Status: models.PatronStatusActive, Tier: "Gold", PledgeCents: 500},
+		{Campaign: models.Campaign{ID: "2", Name: "Two"}, Status: models.PatronStatusActive, PledgeCents: 300},
+		{Campaign: models.Campaign{ID: "3"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Added, []string{"1", "2"}) || len(result.Lapsed) != 0 || result.Total != 3 {
+		t.Errorf("first sync = %+v, want 1 and 2 added", result)
+	}
+	if c, _ := d.GetCampaign("3"); c == nil || c.Name != "Saved by hand" || c.MembershipSyncedAt == nil {
+		t.Errorf("campaign 3 = %+v, want its name kept and the sync recorded", c)
+	}
+
+	// 1's payment fails and 2 disappears from the memberships
+	result, err = d.SyncMemberships([]models.Membership{
+		{Campaign: models.Campaign{ID: "1", Name: "One"}, Status: models.PatronStatusDeclined, Tier: "Gold", PledgeCents: 500},
+		{Campaign: models.Campaign{ID: "3"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Added) != 0 || !reflect.DeepEqual(result.Lapsed, []string{"1", "2"}) {
+		t.Errorf("second sync = %+v, want 1 and 2 lapsed", result)
+	}
+	c, _ := d.GetCampaign("2")
+	if c == nil || c.MembershipStatus != models.PatronStatusFormer || c.PledgeCents != 0 || !c.Lapsed() {
+		t.Errorf("campaign 2 = %+v, want a former patronage", c)
+	}
+
+	// Lapsed campaigns are only reported once
+	result, err = d.SyncMemberships([]models.Membership{
+		{Campaign: models.Campaign{ID: "1", Name: "One"}, Status: models.PatronStatusDeclined},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Lapsed, []string{"3"}) {
+		t.Errorf("third sync = %+v, want only 3 newly lapsed", result)
+	}
+}
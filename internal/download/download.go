@@ -0,0 +1,340 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"patreon-posts/internal/api"
+	"patreon-posts/internal/db"
+	"patreon-posts/internal/models"
+)
+
+// maxResumes is how many times an interrupted transfer is resumed before giving up on the file
+const maxResumes = 3
+
+// maxNameBytes keeps generated file and directory names well under common filesystem limits
+const maxNameBytes = 120
+
+// Status describes the outcome of downloading a single file
+type Status string
+
+const (
+	StatusDownloaded Status = "downloaded"
+	StatusSkipped    Status = "skipped" // Already downloaded by an earlier run
+	StatusFailed     Status = "failed"
+)
+
+// Result reports what happened to one file
+type Result struct {
+	File   models.MediaFile
+	Path   string
+	Status Status
+	Bytes  int64 // Size of the file on disk
+	Err    error
+}
+
+// Downloader saves post attachments, images and audio into a per-campaign/per-post directory tree.
+// Transfers go through the API client, so they share its rate limit and retry policy.
+type Downloader struct {
+	client   *api.Client
+	database *db.Database
+	root     string
+}
+
+// New creates a Downloader that saves files under root
+func New(client *api.Client, database *db.Database, root string) *Downloader {
+	return &Downloader{client: client, database: database, root: root}
+}
+
+// Root returns the directory files are saved under
+func (d *Downloader) Root() string {
+	return d.root
+}
+
+// PostDir returns the directory a post's files are saved in,
+// e.g. "<root>/Creator (123)/2024-01-02 Post title (456)"
+func (d *Downloader) PostDir(campaignID, campaignName string, details *models.PostDetails) string {
+	return filepath.Join(d.root, CampaignDirName(campaignID, campaignName), PostName(details))
+}
+
+// CampaignDirName returns the sanitized directory name for a campaign, e.g. "Creator (123)"
+func CampaignDirName(campaignID, campaignName string) string {
+	if campaignName == "" {
+		return SanitizeName(campaignID)
+	}
+	return SanitizeName(fmt.Sprintf("%s (%s)", campaignName, campaignID))
+}
+
+// PostName returns the sanitized file or directory name for a post, e.g. "2024-01-02 Post title (456)"
+func PostName(details *models.PostDetails) string {
+	// Shorten long titles here so the ID at the end survives
+	name := fmt.Sprintf("%s (%s)", strings.TrimSpace(shorten(details.Title, maxNameBytes-30)), details.ID)
+	if !details.PublishedAt.IsZero() {
+		name = details.PublishedAt.Format("2006-01-02") + " " + name
+	}
+	return SanitizeName(name)
+}
+
+// DownloadPost saves every file attached to a post, skipping files already downloaded.
+// If ctx is cancelled the remaining files are not attempted; partial files are kept for resuming.
+func (d *Downloader) DownloadPost(ctx context.Context, campaignID, campaignName string, details *models.PostDetails) []Result {
+	dir := d.PostDir(campaignID, campaignName, details)
+	names := fileNames(details.Media)
+
+	var results []Result
+	for _, file := range details.Media {
+		if ctx.Err() != nil {
+			break
+		}
+		path := filepath.Join(dir, names[file.ID])
+		result := d.downloadFile(ctx, campaignID, details.ID, file, path)
+		results = append(results, result)
+	}
+	return results
+}
+
+// downloadFile saves a single file unless an earlier run already completed it
+func (d *Downloader) downloadFile(ctx context.Context, campaignID, postID string, file models.MediaFile, path string) Result {
+	result := Result{File: file, Path: path}
+
+	if existing, ok := d.completed(file, path); ok {
+		result.Path = existing.Path
+		result.Bytes = existing.SizeBytes
+		result.Status = StatusSkipped
+		return result
+	}
+
+	if file.DownloadURL == "" {
+		result.Status = StatusFailed
+		result.Err = fmt.Errorf("no download URL for %s", file.FileName)
+		return result
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		result.Status = StatusFailed
+		result.Err = fmt.Errorf("failed to create directory: %w", err)
+		return result
+	}
+
+	size, err := d.fetch(ctx, file, path)
+	if err != nil {
+		result.Status = StatusFailed
+		result.Err = err
+		return result
+	}
+
+	if d.database != nil {
+		if err := d.database.SaveDownload(&db.Download{
+			MediaID:    file.ID,
+			PostID:     postID,
+			CampaignID: campaignID,
+			Path:       path,
+			SizeBytes:  size,
+		}); err != nil {
+			result.Status = StatusFailed
+			result.Err = fmt.Errorf("failed to record download: %w", err)
+			return result
+		}
+	}
+
+	result.Bytes = size
+	result.Status = StatusDownloaded
+	return result
+}
+
+// completed reports whether a file was fully downloaded by an earlier run and is still on disk
+func (d *Downloader) completed(file models.MediaFile, path string) (*db.Download, bool) {
+	if d.database == nil {
+		return nil, false
+	}
+
+	existing, err := d.database.GetDownload(file.ID)
+	if err == nil && existing != nil {
+		if info, err := os.Stat(existing.Path); err == nil && info.Size() == existing.SizeBytes {
+			return existing, true
+		}
+		// The file was moved or deleted since, so fetch it again
+		d.database.DeleteDownload(file.ID)
+	}
+
+	// A file of the expected size without a record, e.g. after the database was reset
+	if info, err := os.Stat(path); err == nil && file.SizeBytes > 0 && info.Size() == file.SizeBytes {
+		return &db.Download{MediaID: file.ID, Path: path, SizeBytes: file.SizeBytes}, true
+	}
+	return nil, false
+}
+
+// fetch downloads a file to path via a ".part" file, resuming where an earlier attempt stopped,
+// and returns its final size
+func (d *Downloader) fetch(ctx context.Context, file models.MediaFile, path string) (int64, error) {
+	part := path + ".part"
+
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+	if file.SizeBytes > 0 && offset > file.SizeBytes {
+		// Larger than the file can be, so it isn't a prefix of it
+		offset = 0
+	}
+
+	for attempt := 1; ; attempt++ {
+		written, err := d.transfer(ctx, file.DownloadURL, part, offset)
+		if err == nil {
+			offset = written
+			break
+		}
+
+		// Resume dropped connections, but not server rejections or cancellation
+		var apiErr *api.APIError
+		if ctx.Err() != nil || errors.As(err, &apiErr) || attempt >= maxResumes || written <= offset {
+			return 0, err
+		}
+		offset = written
+	}
+
+	// Verify against the size Patreon reported for the file
+	if file.SizeBytes > 0 && offset != file.SizeBytes {
+		os.Remove(part)
+		return 0, fmt.Errorf("size mismatch for %s: expected %d bytes, got %d", file.FileName, file.SizeBytes, offset)
+	}
+
+	if err := os.Rename(part, path); err != nil {
+		return 0, fmt.Errorf("failed to move download into place: %w", err)
+	}
+	return offset, nil
+}
+
+// transfer appends the file from offset onwards to the partial file and returns its new size
+func (d *Downloader) transfer(ctx context.Context, fileURL, part string, offset int64) (int64, error) {
+	resp, err := d.client.OpenFile(ctx, fileURL, offset)
+	if err == nil && resp.Offset > offset && offset > 0 {
+		// Resuming there would leave a gap in the file, so start over from the beginning
+		resp.Body.Close()
+		offset = 0
+		resp, err = d.client.OpenFile(ctx, fileURL, 0)
+	}
+	if err != nil {
+		return offset, err
+	}
+	if resp.Offset > offset {
+		resp.Body.Close()
+		return 0, fmt.Errorf("server sent the file from byte %d instead of byte %d", resp.Offset, offset)
+	}
+	defer resp.Body.Close()
+
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return offset, fmt.Errorf("failed to open partial file: %w", err)
+	}
+	defer f.Close()
+
+	// The server may restart from zero if it doesn't support ranges
+	if err := f.Truncate(resp.Offset); err != nil {
+		return offset, fmt.Errorf("failed to truncate partial file: %w", err)
+	}
+	if _, err := f.Seek(resp.Offset, io.SeekStart); err != nil {
+		return offset, fmt.Errorf("failed to seek partial file: %w", err)
+	}
+
+	n, err := io.Copy(f, resp.Body)
+	written := resp.Offset + n
+	if err != nil {
+		return written, fmt.Errorf("failed to download: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return written, fmt.Errorf("failed to write partial file: %w", err)
+	}
+	if resp.Size >= 0 && written != resp.Size {
+		return written, fmt.Errorf("incomplete download: got %d of %d bytes", written, resp.Size)
+	}
+	return written, nil
+}
+
+// fileNames assigns each file a sanitized name that is unique within the post
+func fileNames(media []models.MediaFile) map[string]string {
+	names := make(map[string]string, len(media))
+	used := make(map[string]bool, len(media))
+	for _, file := range media {
+		name := SanitizeName(file.FileName)
+		if file.FileName == "" {
+			name = file.Kind + "-" + file.ID
+		}
+		if used[strings.ToLower(name)] {
+			name = file.ID + "-" + name
+		}
+		used[strings.ToLower(name)] = true
+		names[file.ID] = name
+	}
+	return names
+}
+
+// SanitizeName makes name safe to use as a single path component on common filesystems,
+// replacing separators and reserved characters and keeping the extension when shortening it
+func SanitizeName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			// Drop control characters
+		case strings.ContainsRune(`/\:*?"<>|`, r):
+			b.WriteRune('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	clean := strings.Trim(strings.TrimSpace(b.String()), ". ")
+	if clean == "" {
+		return "untitled"
+	}
+
+	if len(clean) > maxNameBytes {
+		ext := filepath.Ext(clean)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		clean = strings.TrimSpace(shorten(clean, maxNameBytes-len(ext))) + ext
+	}
+
+	// Windows refuses device names regardless of extension
+	stem := strings.ToUpper(strings.TrimSuffix(clean, filepath.Ext(clean)))
+	switch stem {
+	case "CON", "PRN", "AUX", "NUL",
+		"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+		"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9":
+		clean = "_" + clean
+	}
+	return clean
+}
+
+// shorten cuts s to at most n bytes without splitting a UTF-8 sequence
+func shorten(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[:n]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// FormatSize renders a byte count with a binary unit suffix, e.g. "1.5 MB"
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
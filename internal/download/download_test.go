@@ -0,0 +1,51 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"patreon-posts/internal/api"
+)
+
+func TestTransferRestartsWhenRangeSkipsAhead(t *testing.T) {
+	const content = "0123456789"
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		if r.Header.Get("Range") != "" {
+			// Starts past the 4 bytes the partial file holds
+			w.Header().Set("Content-Range", "bytes 8-9/10")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(content[8:]))
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(api.ClientOptions{RateLimit: &api.RateLimit{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	part := filepath.Join(t.TempDir(), "file.part")
+	if err := os.WriteFile(part, []byte(content[:4]), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	d := New(client, nil, "")
+	written, err := d.transfer(context.Background(), server.URL, part, 4)
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	data, _ := os.ReadFile(part)
+	if written != int64(len(content)) || string(data) != content {
+		t.Errorf("got %d bytes %q, want %q", written, data, content)
+	}
+	if len(ranges) != 2 || ranges[0] != "bytes=4-" || ranges[1] != "" {
+		t.Errorf("requests sent Range %q, want a resume then a fresh request", ranges)
+	}
+}
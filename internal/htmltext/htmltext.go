@@ -0,0 +1,361 @@
+package htmltext
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// BlockKind identifies how a block of text should be laid out
+type BlockKind int
+
+const (
+	Paragraph BlockKind = iota
+	Heading
+	ListItem
+	Quote
+	Preformatted
+	Rule
+)
+
+// Block is a single paragraph-level element of a document
+type Block struct {
+	Kind   BlockKind
+	Level  int    // Heading level (1-6) or list nesting depth (1 for top-level items)
+	Marker string // List item marker, e.g. "•" or "3."
+	Text   string // Inline text, with link references such as "[1]" appended to anchor text
+}
+
+// Link is a hyperlink found in the document, numbered by its position in Links
+type Link struct {
+	URL  string
+	Text string // Anchor text, empty for bare links
+}
+
+// Document is post content reduced to blocks of text and footnote-style links
+type Document struct {
+	Blocks []Block
+	Links  []Link
+}
+
+// Parse converts HTML into a Document. Malformed markup is tolerated the way browsers tolerate it.
+func Parse(content string) *Document {
+	root, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		// html.Parse only fails on reader errors, which a strings.Reader never returns
+		return &Document{}
+	}
+
+	p := &parser{doc: &Document{}, linkIndex: make(map[string]int)}
+	p.walk(root)
+	p.flush()
+	return p.doc
+}
+
+// ToText converts HTML into readable plain text
+func ToText(content string) string {
+	return Parse(content).Text()
+}
+
+// Text renders the document as plain text, with a numbered list of links at the end
+func (d *Document) Text() string {
+	var b strings.Builder
+	for i, block := range d.Blocks {
+		if i > 0 {
+			// Consecutive list items stay together; everything else is separated by a blank line
+			if block.Kind == ListItem && d.Blocks[i-1].Kind == ListItem {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(block.PlainText())
+	}
+
+	if len(d.Links) > 0 {
+		b.WriteString("\n\nLinks:")
+		for i, link := range d.Links {
+			fmt.Fprintf(&b, "\n[%d] %s", i+1, link.URL)
+		}
+	}
+	return b.String()
+}
+
+// PlainText renders a single block with plain-text decoration such as bullets and quote markers
+func (b Block) PlainText() string {
+	switch b.Kind {
+	case ListItem:
+		return strings.Repeat("  ", b.Level-1) + b.Marker + " " + b.Text
+	case Quote:
+		return "> " + strings.ReplaceAll(b.Text, "\n", "\n> ")
+	case Rule:
+		return "───"
+	}
+	return b.Text
+}
+
+// listState tracks numbering for an open <ul> or <ol>
+type listState struct {
+	ordered bool
+	next    int
+}
+
+type parser struct {
+	doc       *Document
+	linkIndex map[string]int // URL -> 1-based reference number
+
+	text       strings.Builder
+	kind       BlockKind
+	level      int
+	marker     string
+	lists      []listState
+	quoteDepth int
+	preDepth   int
+	anchor     *anchorText // Text of the anchor being walked, if any
+}
+
+// anchorText collects an anchor's text, which block elements inside it can split across blocks
+type anchorText struct {
+	start   int             // Offset in the current block where the anchor's remaining text starts
+	flushed strings.Builder // Anchor text in blocks that have already ended
+	outer   *anchorText     // Enclosing anchor, if any
+}
+
+// flush ends the current block, dropping it if it holds no text
+func (p *parser) flush() {
+	text := p.text.String()
+	p.text.Reset()
+	for a := p.anchor; a != nil; a = a.outer {
+		a.flushed.WriteString(text[a.start:] + " ")
+		a.start = 0
+	}
+	if p.preDepth == 0 {
+		text = strings.TrimSpace(collapseLines(text))
+	} else {
+		text = strings.Trim(text, "\n")
+	}
+
+	kind := p.kind
+	if kind == Paragraph && p.quoteDepth > 0 {
+		kind = Quote
+	}
+	if text != "" || kind == Rule {
+		p.doc.Blocks = append(p.doc.Blocks, Block{Kind: kind, Level: p.level, Marker: p.marker, Text: text})
+	}
+
+	p.kind, p.level, p.marker = Paragraph, 0, ""
+	if p.preDepth > 0 {
+		p.kind = Preformatted
+	}
+}
+
+// start flushes the current block and begins a new one of the given kind
+func (p *parser) start(kind BlockKind, level int, marker string) {
+	p.flush()
+	p.kind, p.level, p.marker = kind, level, marker
+}
+
+func (p *parser) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		p.writeText(n.Data)
+		return
+	case html.ElementNode:
+		// handled below
+	default:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			p.walk(c)
+		}
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head, atom.Noscript, atom.Template:
+		return
+	case atom.Br:
+		p.text.WriteString("\n")
+		return
+	case atom.Hr:
+		p.start(Rule, 0, "")
+		p.flush()
+		return
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			p.writeText("[image: " + alt + "]")
+		}
+		return
+	case atom.A:
+		p.walkAnchor(n)
+		return
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		p.start(Heading, int(n.Data[1]-'0'), "")
+		p.walkChildren(n)
+		p.flush()
+		return
+	case atom.Ul, atom.Ol:
+		p.flush()
+		p.lists = append(p.lists, listState{ordered: n.DataAtom == atom.Ol, next: 1})
+		p.walkChildren(n)
+		p.lists = p.lists[:len(p.lists)-1]
+		p.flush()
+		return
+	case atom.Li:
+		p.start(ListItem, max(len(p.lists), 1), p.nextMarker())
+		p.walkChildren(n)
+		p.flush()
+		return
+	case atom.Blockquote:
+		p.flush()
+		p.quoteDepth++
+		p.walkChildren(n)
+		p.flush()
+		p.quoteDepth--
+		return
+	case atom.Pre:
+		p.flush()
+		p.preDepth++
+		p.kind = Preformatted
+		p.walkChildren(n)
+		p.flush()
+		p.preDepth--
+		p.kind = Paragraph
+		return
+	case atom.Td, atom.Th:
+		if p.text.Len() > 0 {
+			p.text.WriteString(" | ")
+		}
+		p.walkChildren(n)
+		return
+	}
+
+	if isBlock(n.DataAtom) {
+		// A list item keeps its bullet for its first paragraph; later paragraphs continue the item
+		if p.kind != ListItem || p.text.Len() > 0 {
+			if p.kind == ListItem {
+				p.text.WriteString("\n")
+			} else {
+				p.flush()
+			}
+		}
+		p.walkChildren(n)
+		if p.kind != ListItem {
+			p.flush()
+		}
+		return
+	}
+
+	p.walkChildren(n)
+}
+
+func (p *parser) walkChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		p.walk(c)
+	}
+}
+
+// walkAnchor writes the anchor text followed by a reference to its entry in the link list
+func (p *parser) walkAnchor(n *html.Node) {
+	href := strings.TrimSpace(attr(n, "href"))
+	p.anchor = &anchorText{start: p.text.Len(), outer: p.anchor}
+	p.walkChildren(n)
+	text := strings.TrimSpace(collapseLines(p.anchor.flushed.String() + p.text.String()[p.anchor.start:]))
+	p.anchor = p.anchor.outer
+
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return
+	}
+	bare := text == "" || text == href
+	if text == "" {
+		p.writeText(href)
+	}
+
+	ref, ok := p.linkIndex[href]
+	if !ok {
+		linkText := text
+		if bare {
+			linkText = ""
+		}
+		p.doc.Links = append(p.doc.Links, Link{URL: href, Text: linkText})
+		ref = len(p.doc.Links)
+		p.linkIndex[href] = ref
+	}
+	// A bare URL already shows where it points, so it needs no reference marker
+	if !bare {
+		fmt.Fprintf(&p.text, " [%d]", ref)
+	}
+}
+
+// nextMarker returns the bullet or number for the next item of the innermost list
+func (p *parser) nextMarker() string {
+	if len(p.lists) == 0 {
+		return "•"
+	}
+	list := &p.lists[len(p.lists)-1]
+	if !list.ordered {
+		return "•"
+	}
+	marker := fmt.Sprintf("%d.", list.next)
+	list.next++
+	return marker
+}
+
+// writeText appends text, collapsing whitespace outside <pre>
+func (p *parser) writeText(s string) {
+	if p.preDepth > 0 {
+		p.text.WriteString(s)
+		return
+	}
+	collapsed := strings.Join(strings.Fields(s), " ")
+	if collapsed == "" {
+		if s != "" && p.text.Len() > 0 {
+			p.text.WriteString(" ")
+		}
+		return
+	}
+	if startsWithSpace(s) && p.text.Len() > 0 {
+		p.text.WriteString(" ")
+	}
+	p.text.WriteString(collapsed)
+	if endsWithSpace(s) {
+		p.text.WriteString(" ")
+	}
+}
+
+// collapseLines trims each line and removes runs of spaces left by inline elements
+func collapseLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func startsWithSpace(s string) bool {
+	return s != "" && strings.TrimLeft(s[:1], " \t\r\n\f") == ""
+}
+
+func endsWithSpace(s string) bool {
+	return s != "" && strings.TrimRight(s[len(s)-1:], " \t\r\n\f") == ""
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// isBlock reports whether an element starts a new block
+func isBlock(a atom.Atom) bool {
+	switch a {
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer,
+		atom.Figure, atom.Figcaption, atom.Table, atom.Tr, atom.Dl, atom.Dt, atom.Dd,
+		atom.Main, atom.Aside, atom.Nav, atom.Address, atom.Details, atom.Summary:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package htmltext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		html  string
+		text  string
+		links []Link
+	}{
+		{
+			name: "inline formatting",
+			html: `<p>Hello <b>world</b></p><p>second   paragraph</p>`,
+			text: "Hello world\n\nsecond paragraph",
+		},
+		{
+			name: "block inside anchor",
+			html: `See <a href="https://x.example/">an <div>x</div></a> after`,
+			text: "See an\n\nx\n\n[1] after\n\nLinks:\n[1] https://x.example/",
+			links: []Link{
+				{URL: "https://x.example/", Text: "an x"},
+			},
+		},
+		{
+			name: "paragraphs inside anchor",
+			html: `<a href="https://x.example/"><p>one</p><p>two</p></a>`,
+			text: "one\n\ntwo\n\n[1]\n\nLinks:\n[1] https://x.example/",
+			links: []Link{
+				{URL: "https://x.example/", Text: "one two"},
+			},
+		},
+		{
+			name: "bare link",
+			html: `<p>Go to <a href="https://x.example/">https://x.example/</a> now</p>`,
+			text: "Go to https://x.example/ now\n\nLinks:\n[1] https://x.example/",
+			links: []Link{
+				{URL: "https://x.example/"},
+			},
+		},
+		{
+			name: "empty anchor shows its URL",
+			html: `<p><a href="https://x.example/"></a></p>`,
+			text: "https://x.example/\n\nLinks:\n[1] https://x.example/",
+			links: []Link{
+				{URL: "https://x.example/"},
+			},
+		},
+		{
+			name: "duplicate hrefs share a reference",
+			html: `<p><a href="https://a.example/">one</a> and <a href="https://a.example/">again</a> or <a href="https://b.example/">b</a></p>`,
+			text: "one [1] and again [1] or b [2]\n\nLinks:\n[1] https://a.example/\n[2] https://b.example/",
+			links: []Link{
+				{URL: "https://a.example/", Text: "one"},
+				{URL: "https://b.example/", Text: "b"},
+			},
+		},
+		{
+			name: "fragment and javascript links are dropped",
+			html: `<a href="#top">top</a> <a href="javascript:void(0)">js</a>`,
+			text: "top js",
+		},
+		{
+			name: "preformatted keeps whitespace",
+			html: "<pre>  a\n    b</pre><p>after   this</p>",
+			text: "  a\n    b\n\nafter this",
+		},
+		{
+			name: "lists",
+			html: `<ul><li>one</li><li>two<ul><li>nested</li></ul></li></ul><ol><li>x</li><li>y</li></ol>`,
+			text: "• one\n• two\n  • nested\n1. x\n2. y",
+		},
+		{
+			name: "quote, rule, heading and image",
+			html: `<blockquote><p>quoted</p></blockquote><hr><h2>Title</h2><img alt="cat">`,
+			text: "> quoted\n\n───\n\nTitle\n\n[image: cat]",
+		},
+		{
+			name: "scripts are skipped",
+			html: `<p>a</p><script>alert(1)</script><style>p{}</style><p>b</p>`,
+			text: "a\n\nb",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Parse(tt.html)
+			if got := doc.Text(); got != tt.text {
+				t.Errorf("Text() = %q, want %q", got, tt.text)
+			}
+			if !reflect.DeepEqual(doc.Links, tt.links) {
+				t.Errorf("Links = %#v, want %#v", doc.Links, tt.links)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package links
+
+import (
+	"regexp"
+
+	"patreon-posts/internal/models"
+)
+
+// Provider keys used in config and stored in the database
+const (
+	YouTube     = "youtube"
+	Vimeo       = "vimeo"
+	Twitch      = "twitch"
+	Spotify     = "spotify"
+	SoundCloud  = "soundcloud"
+	Bandcamp    = "bandcamp"
+	GoogleDrive = "gdrive"
+	Mega        = "mega"
+)
+
+// Extractor finds links for a single provider in post content
+type Extractor interface {
+	// Provider returns the stable key used in config and the database, e.g. "youtube"
+	Provider() string
+	// Name returns the display name, e.g. "YouTube"
+	Name() string
+	// Extract returns canonical, deduplicated URLs in order of appearance
+	Extract(content string) []string
+}
+
+// Registry holds the enabled extractors in display order
+type Registry struct {
+	extractors []Extractor
+}
+
+// NewRegistry creates a registry from the given extractors, preserving their order
+func NewRegistry(extractors ...Extractor) *Registry {
+	return &Registry{extractors: extractors}
+}
+
+// Builtin returns every extractor shipped with the application in display order
+func Builtin() []Extractor {
+	return []Extractor{
+		youtubeExtractor{},
+		vimeoExtractor,
+		twitchExtractor,
+		spotifyExtractor,
+		soundcloudExtractor,
+		bandcampExtractor,
+		gdriveExtractor,
+		megaExtractor,
+	}
+}
+
+// NewDefaultRegistry creates a registry of the builtin extractors.
+// Providers mapped to false in enabled are left out; missing providers are enabled.
+func NewDefaultRegistry(enabled map[string]bool) *Registry {
+	var extractors []Extractor
+	for _, e := range Builtin() {
+		if on, ok := enabled[e.Provider()]; ok && !on {
+			continue
+		}
+		extractors = append(extractors, e)
+	}
+	return NewRegistry(extractors...)
+}
+
+// Extract runs every extractor over content and returns the links grouped by provider
+func (r *Registry) Extract(content string) []models.ProviderLink {
+	var result []models.ProviderLink
+	for _, e := range r.extractors {
+		for _, url := range e.Extract(content) {
+			result = append(result, models.ProviderLink{Provider: e.Provider(), URL: url})
+		}
+	}
+	return result
+}
+
+// Apply sets a post's provider links from its content and embed using this registry's
+// extractors, replacing any found with another registry
+func (r *Registry) Apply(details *models.PostDetails) {
+	content := details.Content
+	for _, link := range details.Links {
+		if link.Source == models.LinkSourceEmbed {
+			content += " " + link.URL
+		}
+	}
+	details.ProviderLinks = r.Extract(content)
+	details.YouTubeLinks = URLsFor(details.ProviderLinks, YouTube)
+}
+
+// Providers returns the keys of the registered extractors in display order
+func (r *Registry) Providers() []string {
+	providers := make([]string, len(r.extractors))
+	for i, e := range r.extractors {
+		providers[i] = e.Provider()
+	}
+	return providers
+}
+
+// DisplayName returns the human-readable name of a builtin provider
+func DisplayName(provider string) string {
+	for _, e := range Builtin() {
+		if e.Provider() == provider {
+			return e.Name()
+		}
+	}
+	if provider == "" {
+		return "Other"
+	}
+	return provider
+}
+
+// Order returns the display position of a builtin provider, with unknown providers last
+func Order(provider string) int {
+	builtin := Builtin()
+	for i, e := range builtin {
+		if e.Provider() == provider {
+			return i
+		}
+	}
+	return len(builtin)
+}
+
+// Insert adds a link at the end of its provider's group, so links stay grouped in display
+// order. It returns false and leaves list alone if the URL is already present.
+func Insert(list []models.ProviderLink, link models.ProviderLink) ([]models.ProviderLink, bool) {
+	order := Order(link.Provider)
+	pos := len(list)
+	for i, existing := range list {
+		if existing.URL == link.URL {
+			return list, false
+		}
+		if pos == len(list) && Order(existing.Provider) > order {
+			pos = i
+		}
+	}
+	list = append(list, models.ProviderLink{})
+	copy(list[pos+1:], list[pos:])
+	list[pos] = link
+	return list, true
+}
+
+// URLsFor returns the URLs of links belonging to the given provider
+func URLsFor(links []models.ProviderLink, provider string) []string {
+	var urls []string
+	for _, link := range links {
+		if link.Provider == provider {
+			urls = append(urls, link.URL)
+		}
+	}
+	return urls
+}
+
+// pattern pairs a regex with a function that builds the dedup key and canonical URL from a match
+type pattern struct {
+	re    *regexp.Regexp
+	build func(match []string) (key, url string)
+}
+
+// patternExtractor is a regex-driven Extractor for providers with simple URL shapes
+type patternExtractor struct {
+	provider string
+	name     string
+	patterns []pattern
+}
+
+func (e patternExtractor) Provider() string { return e.provider }
+func (e patternExtractor) Name() string     { return e.name }
+
+func (e patternExtractor) Extract(content string) []string {
+	seen := make(map[string]bool)
+	var urls []string
+	for _, p := range e.patterns {
+		for _, match := range p.re.FindAllStringSubmatch(content, -1) {
+			key, url := p.build(match)
+			if !seen[key] {
+				seen[key] = true
+				urls = append(urls, url)
+			}
+		}
+	}
+	return urls
+}
@@ -0,0 +1,171 @@
+package links
+
+import (
+	"reflect"
+	"testing"
+
+	"patreon-posts/internal/models"
+)
+
+func TestParseYouTubeURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string // Canonical URL; empty when the URL isn't a video, playlist or channel
+		kind models.YouTubeLinkKind
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ", models.YouTubeVideo},
+		{"https://youtu.be/dQw4w9WgXcQ?t=754", "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=754s", models.YouTubeVideo},
+		{"https://m.youtube.com/watch?v=dQw4w9WgXcQ&t=1h2m3s", "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=3723s", models.YouTubeVideo},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&amp;list=PL123abc", "https://www.youtube.com/watch?v=dQw4w9WgXcQ&list=PL123abc", models.YouTubeVideo},
+		{"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ?start=30", "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=30s", models.YouTubeVideo},
+		{"https://www.youtube.com/embed/videoseries?list=PL123abc", "https://www.youtube.com/playlist?list=PL123abc", models.YouTubePlaylist},
+		{"https://www.youtube.com/shorts/dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ", models.YouTubeShort},
+		{"https://www.youtube.com/live/dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ", models.YouTubeLive},
+		{"https://www.youtube.com/playlist?list=PL123abc", "https://www.youtube.com/playlist?list=PL123abc", models.YouTubePlaylist},
+		{"https://www.youtube.com/channel/UCabcdefghijklmnopqrstuv", "https://www.youtube.com/channel/UCabcdefghijklmnopqrstuv", models.YouTubeChannel},
+		{"https://www.youtube.com/@hatfilms", "https://www.youtube.com/@hatfilms", models.YouTubeChannel},
+		{"https://www.youtube.com/c/hatfilms", "https://www.youtube.com/c/hatfilms", models.YouTubeChannel},
+		{"https://youtu.be/dQw4w9WgXcQ).", "https://www.youtube.com/watch?v=dQw4w9WgXcQ", models.YouTubeVideo},
+		{"https://www.youtube.com/watch?v=short", "", ""},
+		{"https://www.youtube.com/playlist", "", ""},
+		{"https://www.youtube.com/feed/subscriptions", "", ""},
+		{"https://www.youtube.com/channel/not-a-channel-id", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			link, ok := ParseYouTubeURL(tt.raw)
+			if ok != (tt.want != "") {
+				t.Fatalf("ok = %v, want %v", ok, tt.want != "")
+			}
+			if !ok {
+				return
+			}
+			if got := link.URL(); got != tt.want {
+				t.Errorf("URL() = %q, want %q", got, tt.want)
+			}
+			if link.Kind != tt.kind {
+				t.Errorf("Kind = %v, want %v", link.Kind, tt.kind)
+			}
+		})
+	}
+}
+
+func TestParseYouTubeLinksMergesDuplicates(t *testing.T) {
+	content := `<a href="https://youtu.be/dQw4w9WgXcQ">a</a>
+		https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=42&list=PL123abc
+		https://www.youtube.com/@hatfilms`
+
+	got := ExtractYouTubeLinks(content)
+	want := []string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=42s&list=PL123abc",
+		"https://www.youtube.com/@hatfilms",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractYouTubeLinks() = %q, want %q", got, want)
+	}
+}
+
+func TestExtractors(t *testing.T) {
+	tests := []struct {
+		name      string
+		extractor Extractor
+		content   string
+		want      []string
+	}{
+		{
+			name:      "vimeo",
+			extractor: vimeoExtractor,
+			content:   `https://vimeo.com/123456 and https://player.vimeo.com/video/123456?h=abc and https://vimeo.com/789`,
+			want:      []string{"https://vimeo.com/123456", "https://vimeo.com/789"},
+		},
+		{
+			name:      "twitch",
+			extractor: twitchExtractor,
+			content:   `https://m.twitch.tv/videos/42 https://www.twitch.tv/somebody`,
+			want:      []string{"https://www.twitch.tv/videos/42"},
+		},
+		{
+			name:      "spotify",
+			extractor: spotifyExtractor,
+			content:   `https://open.spotify.com/embed/episode/abc123?utm=x https://open.spotify.com/episode/abc123`,
+			want:      []string{"https://open.spotify.com/episode/abc123"},
+		},
+		{
+			name:      "soundcloud",
+			extractor: soundcloudExtractor,
+			content:   `https://soundcloud.com/artist/sets/album https://on.soundcloud.com/xyz`,
+			want:      []string{"https://soundcloud.com/artist/sets/album", "https://on.soundcloud.com/xyz"},
+		},
+		{
+			name:      "bandcamp",
+			extractor: bandcampExtractor,
+			content:   `https://artist.bandcamp.com/album/first-one https://artist.bandcamp.com/`,
+			want:      []string{"https://artist.bandcamp.com/album/first-one"},
+		},
+		{
+			name:      "google drive",
+			extractor: gdriveExtractor,
+			content:   `https://drive.google.com/file/d/abc_123/view?usp=sharing https://drive.google.com/open?foo=1&amp;id=abc_123 https://drive.google.com/drive/u/0/folders/folder1`,
+			want:      []string{"https://drive.google.com/file/d/abc_123/view", "https://drive.google.com/drive/folders/folder1"},
+		},
+		{
+			name:      "mega keeps the key",
+			extractor: megaExtractor,
+			content:   `https://mega.nz/file/abc#key1 https://mega.nz/#F!def!key2 https://mega.co.nz/#!abc!key1`,
+			want:      []string{"https://mega.nz/file/abc#key1", "https://mega.nz/folder/def#key2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.extractor.Extract(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDefaultRegistry(t *testing.T) {
+	registry := NewDefaultRegistry(map[string]bool{Vimeo: false, YouTube: true})
+	for _, provider := range registry.Providers() {
+		if provider == Vimeo {
+			t.Fatal("disabled provider is registered")
+		}
+	}
+
+	got := registry.Extract("https://vimeo.com/1 https://youtu.be/dQw4w9WgXcQ https://mega.nz/file/abc#key")
+	want := []models.ProviderLink{
+		{Provider: YouTube, URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{Provider: Mega, URL: "https://mega.nz/file/abc#key"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract() = %+v, want %+v", got, want)
+	}
+}
+
+func TestInsert(t *testing.T) {
+	var list []models.ProviderLink
+	for _, link := range []models.ProviderLink{
+		{Provider: Mega, URL: "https://mega.nz/file/a#k"},
+		{Provider: YouTube, URL: "https://www.youtube.com/watch?v=1"},
+		{Provider: Vimeo, URL: "https://vimeo.com/1"},
+		{Provider: YouTube, URL: "https://www.youtube.com/watch?v=2"},
+		{Provider: YouTube, URL: "https://www.youtube.com/watch?v=1"},
+	} {
+		list, _ = Insert(list, link)
+	}
+
+	want := []models.ProviderLink{
+		{Provider: YouTube, URL: "https://www.youtube.com/watch?v=1"},
+		{Provider: YouTube, URL: "https://www.youtube.com/watch?v=2"},
+		{Provider: Vimeo, URL: "https://vimeo.com/1"},
+		{Provider: Mega, URL: "https://mega.nz/file/a#k"},
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("Insert() = %+v, want %+v", list, want)
+	}
+	if _, added := Insert(list, want[2]); added {
+		t.Error("Insert() added a URL that is already present")
+	}
+}
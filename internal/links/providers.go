@@ -0,0 +1,128 @@
+package links
+
+import "regexp"
+
+var vimeoExtractor = patternExtractor{
+	provider: Vimeo,
+	name:     "Vimeo",
+	patterns: []pattern{
+		{
+			re: regexp.MustCompile(`https?://(?:www\.|player\.)?vimeo\.com/(?:video/)?(\d+)`),
+			build: func(m []string) (string, string) {
+				return m[1], "https://vimeo.com/" + m[1]
+			},
+		},
+	},
+}
+
+var twitchExtractor = patternExtractor{
+	provider: Twitch,
+	name:     "Twitch",
+	patterns: []pattern{
+		{
+			re: regexp.MustCompile(`https?://(?:www\.|m\.)?twitch\.tv/videos/(\d+)`),
+			build: func(m []string) (string, string) {
+				return m[1], "https://www.twitch.tv/videos/" + m[1]
+			},
+		},
+	},
+}
+
+var spotifyExtractor = patternExtractor{
+	provider: Spotify,
+	name:     "Spotify",
+	patterns: []pattern{
+		{
+			re: regexp.MustCompile(`https?://open\.spotify\.com/(?:embed/)?(episode|show|track|album|playlist)/([A-Za-z0-9]+)`),
+			build: func(m []string) (string, string) {
+				return m[1] + "/" + m[2], "https://open.spotify.com/" + m[1] + "/" + m[2]
+			},
+		},
+	},
+}
+
+var soundcloudExtractor = patternExtractor{
+	provider: SoundCloud,
+	name:     "SoundCloud",
+	patterns: []pattern{
+		{
+			re: regexp.MustCompile(`https?://(?:www\.|m\.)?soundcloud\.com/([\w-]+/(?:sets/)?[\w-]+)`),
+			build: func(m []string) (string, string) {
+				return m[1], "https://soundcloud.com/" + m[1]
+			},
+		},
+		{
+			re: regexp.MustCompile(`https?://on\.soundcloud\.com/([\w-]+)`),
+			build: func(m []string) (string, string) {
+				return "on/" + m[1], "https://on.soundcloud.com/" + m[1]
+			},
+		},
+	},
+}
+
+var bandcampExtractor = patternExtractor{
+	provider: Bandcamp,
+	name:     "Bandcamp",
+	patterns: []pattern{
+		{
+			re: regexp.MustCompile(`https?://([a-z0-9-]+)\.bandcamp\.com/(track|album)/([\w-]+)`),
+			build: func(m []string) (string, string) {
+				url := "https://" + m[1] + ".bandcamp.com/" + m[2] + "/" + m[3]
+				return url, url
+			},
+		},
+	},
+}
+
+var gdriveExtractor = patternExtractor{
+	provider: GoogleDrive,
+	name:     "Google Drive",
+	patterns: []pattern{
+		{
+			re: regexp.MustCompile(`https?://drive\.google\.com/file/d/([\w-]+)`),
+			build: func(m []string) (string, string) {
+				return m[1], "https://drive.google.com/file/d/" + m[1] + "/view"
+			},
+		},
+		{
+			re: regexp.MustCompile(`https?://drive\.google\.com/(?:open|uc)\?(?:[^"'\s<>]*?&(?:amp;)?)?id=([\w-]+)`),
+			build: func(m []string) (string, string) {
+				return m[1], "https://drive.google.com/file/d/" + m[1] + "/view"
+			},
+		},
+		{
+			re: regexp.MustCompile(`https?://drive\.google\.com/drive/(?:u/\d+/)?folders/([\w-]+)`),
+			build: func(m []string) (string, string) {
+				return m[1], "https://drive.google.com/drive/folders/" + m[1]
+			},
+		},
+	},
+}
+
+var megaExtractor = patternExtractor{
+	provider: Mega,
+	name:     "Mega",
+	patterns: []pattern{
+		{
+			// The fragment holds the decryption key, so it must be kept
+			re: regexp.MustCompile(`https?://mega\.(?:nz|co\.nz)/(file|folder)/([\w-]+)(#[\w-]+)?`),
+			build: func(m []string) (string, string) {
+				return m[2], "https://mega.nz/" + m[1] + "/" + m[2] + m[3]
+			},
+		},
+		{
+			re: regexp.MustCompile(`https?://mega\.(?:nz|co\.nz)/#(F?)!([\w-]+)(![\w-]+)?`),
+			build: func(m []string) (string, string) {
+				kind := "file"
+				if m[1] == "F" {
+					kind = "folder"
+				}
+				key := ""
+				if m[3] != "" {
+					key = "#" + m[3][1:]
+				}
+				return m[2], "https://mega.nz/" + kind + "/" + m[2] + key
+			},
+		},
+	},
+}
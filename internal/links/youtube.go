@@ -0,0 +1,177 @@
+package links
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"patreon-posts/internal/models"
+)
+
+// youtubeURLRe finds candidate URLs on every YouTube host; the path is classified afterwards
+var youtubeURLRe = regexp.MustCompile(
+	`https?://(?:(?:www|m|music)\.)?(?:youtube\.com|youtube-nocookie\.com|youtu\.be)/[^\s"'<>]*`)
+
+var (
+	videoIDRe   = regexp.MustCompile(`^[a-zA-Z0-9_-]{11}$`)
+	listIDRe    = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	channelIDRe = regexp.MustCompile(`^UC[a-zA-Z0-9_-]{22}$`)
+	handleRe    = regexp.MustCompile(`^@[a-zA-Z0-9._-]+$`)
+	legacyRe    = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	durationRe  = regexp.MustCompile(`^(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s?)?$`)
+)
+
+// youtubeExtractor finds YouTube video, playlist and channel links
+type youtubeExtractor struct{}
+
+func (youtubeExtractor) Provider() string { return YouTube }
+func (youtubeExtractor) Name() string     { return "YouTube" }
+
+func (youtubeExtractor) Extract(content string) []string {
+	return ExtractYouTubeLinks(content)
+}
+
+// ExtractYouTubeLinks finds all YouTube URLs in the given text, returning canonical URLs
+// that keep start offsets and playlist IDs
+func ExtractYouTubeLinks(content string) []string {
+	parsed := ParseYouTubeLinks(content)
+	urls := make([]string, len(parsed))
+	for i, link := range parsed {
+		urls[i] = link.URL()
+	}
+	return urls
+}
+
+// ParseYouTubeLinks finds all YouTube links in the given text in order of appearance.
+// Links to the same video are merged, keeping the first start offset and playlist seen.
+func ParseYouTubeLinks(content string) []models.YouTubeLink {
+	index := make(map[string]int)
+	var result []models.YouTubeLink
+
+	for _, raw := range youtubeURLRe.FindAllString(content, -1) {
+		link, ok := ParseYouTubeURL(raw)
+		if !ok {
+			continue
+		}
+		key := link.Key()
+		if i, seen := index[key]; seen {
+			if result[i].StartSeconds == 0 {
+				result[i].StartSeconds = link.StartSeconds
+			}
+			if result[i].PlaylistID == "" {
+				result[i].PlaylistID = link.PlaylistID
+			}
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, link)
+	}
+
+	return result
+}
+
+// ParseYouTubeURL classifies a single YouTube URL, reporting false for pages that
+// are not a video, playlist or channel
+func ParseYouTubeURL(raw string) (models.YouTubeLink, bool) {
+	// Content comes from HTML, so query separators may still be entity-encoded
+	raw = html.UnescapeString(raw)
+	raw = strings.TrimRight(raw, ".,;:!?)]")
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return models.YouTubeLink{}, false
+	}
+
+	query := u.Query()
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	link := models.YouTubeLink{
+		StartSeconds: parseStart(query),
+		PlaylistID:   validList(query.Get("list")),
+	}
+
+	if strings.EqualFold(u.Hostname(), "youtu.be") {
+		link.Kind = models.YouTubeVideo
+		link.VideoID = segments[0]
+		return link, videoIDRe.MatchString(link.VideoID)
+	}
+
+	switch segments[0] {
+	case "watch":
+		link.Kind = models.YouTubeVideo
+		link.VideoID = query.Get("v")
+	case "embed", "v", "e":
+		if len(segments) < 2 {
+			return link, false
+		}
+		if segments[1] == "videoseries" {
+			link.Kind = models.YouTubePlaylist
+			return link, link.PlaylistID != ""
+		}
+		link.Kind = models.YouTubeVideo
+		link.VideoID = segments[1]
+	case "shorts":
+		link.Kind = models.YouTubeShort
+		if len(segments) > 1 {
+			link.VideoID = segments[1]
+		}
+	case "live":
+		link.Kind = models.YouTubeLive
+		if len(segments) > 1 {
+			link.VideoID = segments[1]
+		}
+	case "playlist":
+		link.Kind = models.YouTubePlaylist
+		return link, link.PlaylistID != ""
+	case "channel":
+		if len(segments) < 2 || !channelIDRe.MatchString(segments[1]) {
+			return link, false
+		}
+		return channelLink("channel/" + segments[1]), true
+	case "c", "user":
+		if len(segments) < 2 || !legacyRe.MatchString(segments[1]) {
+			return link, false
+		}
+		return channelLink(segments[0] + "/" + segments[1]), true
+	default:
+		if handleRe.MatchString(segments[0]) {
+			return channelLink(segments[0]), true
+		}
+		return link, false
+	}
+
+	return link, videoIDRe.MatchString(link.VideoID)
+}
+
+// channelLink builds a channel link; timestamps and playlists don't apply to channels
+func channelLink(path string) models.YouTubeLink {
+	return models.YouTubeLink{Kind: models.YouTubeChannel, Channel: path}
+}
+
+// validList returns the playlist ID if it looks valid, otherwise ""
+func validList(list string) string {
+	if listIDRe.MatchString(list) {
+		return list
+	}
+	return ""
+}
+
+// parseStart reads the start offset from t= or start=, accepting "754", "754s" and "12m34s"
+func parseStart(query url.Values) int {
+	value := query.Get("t")
+	if value == "" {
+		value = query.Get("start")
+	}
+	if value == "" {
+		return 0
+	}
+	m := durationRe.FindStringSubmatch(value)
+	if m == nil {
+		return 0
+	}
+	hours, _ := strconv.Atoi(m[1])
+	minutes, _ := strconv.Atoi(m[2])
+	seconds, _ := strconv.Atoi(m[3])
+	return hours*3600 + minutes*60 + seconds
+}
@@ -0,0 +1,38 @@
+package models
+
+// CampaignAttributes contains the campaign fields we care about
+type CampaignAttributes struct {
+	Name   string `json:"name"`
+	Vanity string `json:"vanity"`
+	URL    string `json:"url"`
+}
+
+// ResourceResponse represents an API response holding a single resource with its includes
+type ResourceResponse struct {
+	Data     Resource   `json:"data"`
+	Included []Resource `json:"included"`
+}
+
+// ResourceListResponse represents an API response holding a list of resources with their includes
+type ResourceListResponse struct {
+	Data     []Resource      `json:"data"`
+	Included []Resource      `json:"included"`
+	Links    PaginationLinks `json:"links"`
+}
+
+// Campaign identifies a creator's campaign
+type Campaign struct {
+	ID     string
+	Name   string // Creator name shown on the campaign page
+	Vanity string // Short name used in patreon.com/<vanity> URLs
+	URL    string
+}
+
+// FromCampaignResource converts a "campaign" resource into a Campaign
+func FromCampaignResource(r Resource) (Campaign, error) {
+	var attrs CampaignAttributes
+	if err := r.DecodeAttributes(&attrs); err != nil {
+		return Campaign{}, err
+	}
+	return Campaign{ID: r.ID, Name: attrs.Name, Vanity: attrs.Vanity, URL: attrs.URL}, nil
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// CommentsResponse represents the API response for a page of post comments.
+// Replies and commenters arrive as included resources.
+type CommentsResponse struct {
+	Data     []Resource      `json:"data"`
+	Included []Resource      `json:"included"`
+	Links    PaginationLinks `json:"links"`
+}
+
+// CommentAttributes contains the comment fields we care about
+type CommentAttributes struct {
+	Body        string     `json:"body"`
+	Created     time.Time  `json:"created"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+	IsByCreator bool       `json:"is_by_creator"`
+	VoteSum     int        `json:"vote_sum"`
+	ReplyCount  int        `json:"reply_count"`
+}
+
+// UserAttributes contains the public profile fields of a commenter
+type UserAttributes struct {
+	FullName string `json:"full_name"`
+	Vanity   string `json:"vanity"`
+}
+
+// Comment is a comment on a post with its threaded replies
+type Comment struct {
+	ID          string         `json:"id"`
+	PostID      string         `json:"post_id"`
+	ParentID    string         `json:"parent_id,omitempty"` // Empty for top-level comments
+	Author      string         `json:"author"`
+	Body        string         `json:"body"`
+	CreatedAt   time.Time      `json:"created_at"`
+	IsByCreator bool           `json:"is_by_creator"`
+	VoteSum     int            `json:"vote_sum"`
+	ReplyCount  int            `json:"reply_count"`
+	Links       []ProviderLink `json:"links,omitempty"` // Links found in the body
+	Replies     []Comment      `json:"replies,omitempty"`
+}
+
+// CommentsPage represents a page of top-level comments with pagination info
+type CommentsPage struct {
+	Comments   []Comment
+	NextCursor string
+	HasMore    bool
+}
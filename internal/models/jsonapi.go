@@ -0,0 +1,90 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Resource is a generic JSON:API resource object, as found in a response's included[] array
+type Resource struct {
+	ID            string                  `json:"id"`
+	Type          string                  `json:"type"`
+	Attributes    json.RawMessage         `json:"attributes"`
+	Relationships map[string]Relationship `json:"relationships"`
+}
+
+// DecodeAttributes unmarshals the resource's attributes into v
+func (r Resource) DecodeAttributes(v any) error {
+	if len(r.Attributes) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.Attributes, v)
+}
+
+// ResourceIdentifier points at a resource by type and ID
+type ResourceIdentifier struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// Relationship holds the identifiers of related resources.
+// JSON:API uses a single object for to-one and an array for to-many relationships; both decode to Data.
+type Relationship struct {
+	Data []ResourceIdentifier
+}
+
+// UnmarshalJSON accepts {"data": {...}}, {"data": [...]} and {"data": null}
+func (r *Relationship) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	data := bytes.TrimSpace(raw.Data)
+	switch {
+	case len(data) == 0 || bytes.Equal(data, []byte("null")):
+		r.Data = nil
+	case data[0] == '[':
+		return json.Unmarshal(data, &r.Data)
+	default:
+		var one ResourceIdentifier
+		if err := json.Unmarshal(data, &one); err != nil {
+			return err
+		}
+		r.Data = []ResourceIdentifier{one}
+	}
+	return nil
+}
+
+// Included indexes a response's included[] resources so relationships can be resolved
+type Included struct {
+	index map[ResourceIdentifier]Resource
+}
+
+// NewIncluded builds an index over the given resources
+func NewIncluded(resources []Resource) Included {
+	index := make(map[ResourceIdentifier]Resource, len(resources))
+	for _, r := range resources {
+		index[ResourceIdentifier{ID: r.ID, Type: r.Type}] = r
+	}
+	return Included{index: index}
+}
+
+// Resolve returns the included resource with the given identifier
+func (in Included) Resolve(id ResourceIdentifier) (Resource, bool) {
+	r, ok := in.index[id]
+	return r, ok
+}
+
+// ResolveAll returns the included resources a relationship points at, skipping any not included
+func (in Included) ResolveAll(rel Relationship) []Resource {
+	var resources []Resource
+	for _, id := range rel.Data {
+		if r, ok := in.Resolve(id); ok {
+			resources = append(resources, r)
+		}
+	}
+	return resources
+}
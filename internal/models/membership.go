@@ -0,0 +1,41 @@
+package models
+
+import "fmt"
+
+// Patron statuses reported for a membership
+const (
+	PatronStatusActive   = "active_patron"
+	PatronStatusDeclined = "declined_patron" // Last payment failed
+	PatronStatusFormer   = "former_patron"   // Pledge cancelled
+)
+
+// MemberAttributes contains the membership fields we care about
+type MemberAttributes struct {
+	PatronStatus                 string `json:"patron_status"` // Empty for free members who follow the creator
+	CurrentlyEntitledAmountCents int    `json:"currently_entitled_amount_cents"`
+}
+
+// TierAttributes contains the reward tier fields we care about
+type TierAttributes struct {
+	Title       string `json:"title"`
+	AmountCents int    `json:"amount_cents"`
+}
+
+// Membership is the current user's relationship with a campaign
+type Membership struct {
+	Campaign    Campaign
+	Status      string // One of the PatronStatus constants, or empty for free members
+	Tier        string // Title of the entitled tier, empty if none
+	PledgeCents int    // Amount currently pledged per billing period in cents
+}
+
+// Lapsed reports whether the user used to pay for the campaign but no longer does
+func (m Membership) Lapsed() bool {
+	return m.Status == PatronStatusDeclined || m.Status == PatronStatusFormer
+}
+
+// FormatCents formats an amount in cents as "5.00". Pledges are in the campaign's
+// currency, which the API doesn't report alongside the amount, so no symbol is added.
+func FormatCents(cents int) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
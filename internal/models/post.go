@@ -22,6 +22,22 @@ type PaginationLinks struct {
 	First string `json:"first"`
 }
 
+// ErrorResponse represents a JSON:API error response body
+// Patreon returns: {"errors": [{"code": 1, "code_name": "Unauthorized", "detail": "...", "status": "401", "title": "..."}]}
+type ErrorResponse struct {
+	Errors []ErrorObject `json:"errors"`
+}
+
+// ErrorObject is a single entry of a JSON:API errors array
+type ErrorObject struct {
+	ID       string `json:"id"`
+	Code     int    `json:"code"`
+	CodeName string `json:"code_name"`
+	Status   string `json:"status"`
+	Title    string `json:"title"`
+	Detail   string `json:"detail"`
+}
+
 // PostsPage represents a page of posts with pagination info
 type PostsPage struct {
 	Posts      []Post
@@ -56,6 +72,7 @@ type Post struct {
 	PatreonURL         string
 	CurrentUserCanView bool
 	PublishedAt        time.Time
+	CommenterCount     int  // Number of people who commented on the post
 	DetailsCached      bool // Whether the post details have been fetched and cached
 }
 
@@ -69,20 +86,23 @@ func FromPostData(data PostData) Post {
 		PatreonURL:         data.Attributes.PatreonURL,
 		CurrentUserCanView: data.Attributes.CurrentUserCanView,
 		PublishedAt:        data.Attributes.PublishedAt,
+		CommenterCount:     data.Attributes.CommenterCount,
 		DetailsCached:      false,
 	}
 }
 
 // PostDetailResponse represents the API response for a single post
 type PostDetailResponse struct {
-	Data PostDetailData `json:"data"`
+	Data     PostDetailData `json:"data"`
+	Included []Resource     `json:"included"`
 }
 
 // PostDetailData represents the post data in detail response
 type PostDetailData struct {
-	ID         string               `json:"id"`
-	Type       string               `json:"type"`
-	Attributes PostDetailAttributes `json:"attributes"`
+	ID            string                  `json:"id"`
+	Type          string                  `json:"type"`
+	Attributes    PostDetailAttributes    `json:"attributes"`
+	Relationships map[string]Relationship `json:"relationships"`
 }
 
 // PostDetailAttributes contains detailed post attributes
@@ -98,16 +118,95 @@ type PostDetailAttributes struct {
 type Embed struct {
 	URL         string `json:"url"`
 	Provider    string `json:"provider"`
+	Subject     string `json:"subject"`
 	Description string `json:"description"`
 }
 
+// Where a Link was found in a post
+const (
+	LinkSourceContent = "content" // An <a href> in the post body
+	LinkSourceEmbed   = "embed"   // The post's embedded media
+)
+
+// Link is a hyperlink found in a post
+type Link struct {
+	URL      string `json:"url"`
+	Text     string `json:"text,omitempty"`     // Anchor text, or the embed subject for embeds
+	Source   string `json:"source"`             // LinkSourceContent or LinkSourceEmbed
+	Provider string `json:"provider,omitempty"` // Embed provider name as reported by Patreon, e.g. "YouTube"
+}
+
+// ProviderLink is a link to content hosted by a known provider such as YouTube or Vimeo
+type ProviderLink struct {
+	Provider string `json:"provider"` // Provider key, e.g. "youtube"
+	URL      string `json:"url"`
+}
+
+// MediaAttributes contains the attributes of a "media" resource
+type MediaAttributes struct {
+	FileName    string            `json:"file_name"`
+	SizeBytes   int64             `json:"size_bytes"`
+	MimeType    string            `json:"mimetype"`
+	DownloadURL string            `json:"download_url"`
+	ImageURLs   map[string]string `json:"image_urls"`
+}
+
+// Kinds of MediaFile, named after the post relationship they were found through
+const (
+	MediaKindAttachment = "attachment"
+	MediaKindImage      = "image"
+	MediaKindAudio      = "audio"
+	MediaKindMedia      = "media"
+)
+
+// MediaFile is a downloadable file attached to a post
+type MediaFile struct {
+	ID          string `json:"id"`
+	Kind        string `json:"kind"`
+	FileName    string `json:"file_name"`
+	SizeBytes   int64  `json:"size_bytes,omitempty"`
+	MimeType    string `json:"mime_type,omitempty"`
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// FromMediaResource converts an included "media" resource into a MediaFile
+func FromMediaResource(r Resource, kind string) (MediaFile, error) {
+	var attrs MediaAttributes
+	if err := r.DecodeAttributes(&attrs); err != nil {
+		return MediaFile{}, err
+	}
+
+	downloadURL := attrs.DownloadURL
+	if downloadURL == "" {
+		// Images only expose sized variants; prefer the original upload
+		for _, key := range []string{"original", "default", "url"} {
+			if u := attrs.ImageURLs[key]; u != "" {
+				downloadURL = u
+				break
+			}
+		}
+	}
+
+	return MediaFile{
+		ID:          r.ID,
+		Kind:        kind,
+		FileName:    attrs.FileName,
+		SizeBytes:   attrs.SizeBytes,
+		MimeType:    attrs.MimeType,
+		DownloadURL: downloadURL,
+	}, nil
+}
+
 // PostDetails contains the extracted details from a post
 type PostDetails struct {
-	ID           string
-	Title        string
-	Content      string
-	Description  string // HTML-stripped content
-	PostType     string
-	PublishedAt  time.Time
-	YouTubeLinks []string
+	ID            string
+	Title         string
+	Content       string
+	Description   string // HTML-stripped content
+	PostType      string
+	PublishedAt   time.Time
+	YouTubeLinks  []string
+	ProviderLinks []ProviderLink // Links from every enabled provider, grouped by provider
+	Links         []Link         // Every hyperlink and embed in the post, in order of appearance
+	Media         []MediaFile    // Attachments, images and audio files on the post
 }
@@ -0,0 +1,39 @@
+package models
+
+import "strings"
+
+// CurrentUserAttributes contains the account fields of the logged in user
+type CurrentUserAttributes struct {
+	FullName string `json:"full_name"`
+	Vanity   string `json:"vanity"`
+	Email    string `json:"email"`
+}
+
+// User is the account the session cookies belong to
+type User struct {
+	ID          string
+	FullName    string
+	Vanity      string
+	Email       string
+	Memberships []Membership
+}
+
+// EmailDomain returns the part of the email address after the @, so the account
+// can be identified without printing the full address
+func (u User) EmailDomain() string {
+	if i := strings.LastIndex(u.Email, "@"); i >= 0 {
+		return u.Email[i+1:]
+	}
+	return ""
+}
+
+// ActiveMemberships returns the memberships with a current paid pledge
+func (u User) ActiveMemberships() []Membership {
+	var active []Membership
+	for _, m := range u.Memberships {
+		if m.Status == PatronStatusActive {
+			active = append(active, m)
+		}
+	}
+	return active
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// YouTubeLinkKind identifies what a YouTube link points at
+type YouTubeLinkKind string
+
+const (
+	YouTubeVideo    YouTubeLinkKind = "video"
+	YouTubeShort    YouTubeLinkKind = "short"
+	YouTubeLive     YouTubeLinkKind = "live"
+	YouTubePlaylist YouTubeLinkKind = "playlist"
+	YouTubeChannel  YouTubeLinkKind = "channel"
+)
+
+// YouTubeLink is a parsed YouTube URL that keeps timestamps and playlist context
+type YouTubeLink struct {
+	Kind         YouTubeLinkKind `json:"kind"`
+	VideoID      string          `json:"video_id,omitempty"`
+	StartSeconds int             `json:"start_seconds,omitempty"` // Offset from t= or start=
+	PlaylistID   string          `json:"playlist_id,omitempty"`   // From list=, or the playlist itself
+	Channel      string          `json:"channel,omitempty"`       // Channel path such as "channel/UC...", "@handle" or "c/name"
+}
+
+// Key returns the identity used to deduplicate links: the video ID when present,
+// otherwise the playlist or channel
+func (l YouTubeLink) Key() string {
+	switch {
+	case l.VideoID != "":
+		return "video:" + l.VideoID
+	case l.PlaylistID != "":
+		return "playlist:" + l.PlaylistID
+	default:
+		return "channel:" + l.Channel
+	}
+}
+
+// URL returns the canonical URL for the link, preserving the start offset and playlist
+func (l YouTubeLink) URL() string {
+	switch l.Kind {
+	case YouTubePlaylist:
+		return "https://www.youtube.com/playlist?list=" + url.QueryEscape(l.PlaylistID)
+	case YouTubeChannel:
+		return "https://www.youtube.com/" + l.Channel
+	}
+
+	u := "https://www.youtube.com/watch?v=" + l.VideoID
+	if l.StartSeconds > 0 {
+		u += fmt.Sprintf("&t=%ds", l.StartSeconds)
+	}
+	if l.PlaylistID != "" {
+		u += "&list=" + url.QueryEscape(l.PlaylistID)
+	}
+	return u
+}
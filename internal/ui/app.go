@@ -1,8 +1,11 @@
 package ui
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -15,7 +18,11 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"patreon-posts/internal/api"
+	"patreon-posts/internal/config"
 	"patreon-posts/internal/db"
+	"patreon-posts/internal/download"
+	"patreon-posts/internal/htmltext"
+	"patreon-posts/internal/links"
 	"patreon-posts/internal/models"
 )
 
@@ -92,10 +99,35 @@ var (
 			Foreground(lipgloss.Color("#FF0000")).
 			Bold(true)
 
+	providerStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#9d8cff")).
+			Bold(true)
+
 	descriptionStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("#b0b0b0")).
 				PaddingLeft(2)
 
+	// Post content styles
+	contentHeadingStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#00D4AA")).
+				Bold(true).
+				PaddingLeft(2)
+
+	listMarkerStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF424D"))
+
+	quoteStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#9d9db5")).
+			Italic(true).
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderLeft(true).
+			BorderForeground(lipgloss.Color("#3d3d5c")).
+			MarginLeft(2).
+			PaddingLeft(1)
+
+	footnoteStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#666680"))
+
 	// Clipboard panel styles
 	clipboardPanelStyle = lipgloss.NewStyle().
 				Padding(0, 1).
@@ -148,6 +180,7 @@ type Model struct {
 	cursor          int
 	client          *api.Client
 	database        *db.Database
+	profile         string // Account profile in use, shown in the title bar
 	input           textinput.Model
 	spinner         spinner.Model
 	viewport        viewport.Model
@@ -159,25 +192,55 @@ type Model struct {
 	loadingMsg      string
 	postDetails     *models.PostDetails
 	cachedDetails   *db.CachedPost
-	clipboardLinks  []string // Links collected in clipboard
-	clipboardCursor int      // Cursor position in clipboard
-	linkCursor      int      // Cursor for YouTube links in details view
-	statusMessage   string   // Temporary status message
+	clipboardLinks  []models.ProviderLink // Links collected in clipboard, grouped by provider
+	clipboardCursor int                   // Cursor position in clipboard
+	linkCursor      int                   // Cursor for provider links in details view
+	statusMessage   string                // Temporary status message
 	// Pagination
 	currentPage   int      // Current page number (1-indexed for display)
 	nextCursor    string   // Cursor for next page
 	cursorHistory []string // History of cursors for going back
 	totalPosts    int      // Total posts available
 	hasMorePages  bool     // Whether there are more pages
+	pastDate      bool     // Whether the date filter cut the current page short, so later pages are all older
 	// Campaign selection
 	savedCampaigns  []db.SavedCampaign
-	campaignCursor  int             // Cursor for campaign selection
-	inputStep       int             // 0 = selection, 1 = entering ID, 2 = entering name, 3 = entering date
-	nameInput       textinput.Model // Input for campaign name
-	dateInput       textinput.Model // Input for date filter
-	pendingID       string          // ID entered in step 1, waiting for name
-	publishedAfter  string          // Date filter (YYYY-MM-DD format)
-	editingDateOnly bool            // True when editing date from selection screen
+	campaignCursor  int                // Cursor for campaign selection
+	inputStep       int                // 0 = selection, 1 = entering ID, 2 = entering name, 3 = entering date
+	nameInput       textinput.Model    // Input for campaign name
+	dateInput       textinput.Model    // Input for date filter
+	pendingID       string             // ID entered in step 1, waiting for name
+	resolving       bool               // True while the step 1 input is being resolved to a campaign
+	cancelResolve   context.CancelFunc // Cancels the campaign lookup in progress, if any
+	resolveErr      error              // Why the step 1 input couldn't be resolved, if it couldn't
+	syncing         bool               // True while memberships are being imported
+	cancelSync      context.CancelFunc // Cancels the membership import in progress, if any
+	publishedAfter  string             // Date filter (YYYY-MM-DD format)
+	filterEdited    bool               // True once the date filter is changed in the TUI, which then beats campaign settings
+	editingDateOnly bool               // True when editing date from selection screen
+	renaming        bool               // True when step 2 renames the selected campaign instead of adding one
+	// Per-campaign settings
+	cfg      *config.Config          // Config the campaign settings come from, if any
+	cfgPath  string                  // Config file campaign edits are written back to, if any
+	campaign config.CampaignSettings // Settings of the open campaign
+	// In-flight request tracking
+	cancelRequest context.CancelFunc // Cancels the request the loading view is waiting on
+	requestSeq    int                // Incremented per request so stale responses can be ignored
+	loadingReturn viewState          // View to restore when loading is cancelled
+	returnPage    int                // Page number to restore when loading is cancelled
+	returnHistory []string           // Cursor history to restore when loading is cancelled
+	// Session recovery
+	cookieInput    textinput.Model // Input for pasting new cookies from the error screen
+	pastingCookies bool            // True while the cookie input is shown on the error screen
+	// Media downloads
+	downloader     *download.Downloader // Saves post attachments under the download directory
+	downloading    string               // Title of the post whose files are being downloaded, if any
+	cancelDownload context.CancelFunc   // Cancels the download in progress, if any
+	// Comments pane
+	showComments    bool                 // True while the comments pane is shown in the details view
+	comments        *models.CommentsPage // Comments loaded so far for the post in the details view
+	commentsLoading bool                 // True while a page of comments is being fetched
+	cancelComments  context.CancelFunc   // Cancels the page of comments being fetched, if any
 }
 
 // PostsFetchedMsg is sent when posts are fetched
@@ -188,12 +251,14 @@ type PostsFetchedMsg struct {
 	Total      int
 	Err        error
 	FromCache  bool
+	seq        int
 }
 
 // PostDetailsFetchedMsg is sent when post details are fetched
 type PostDetailsFetchedMsg struct {
 	Details *models.PostDetails
 	Err     error
+	seq     int
 }
 
 // CacheUpdatedMsg is sent when cache status is updated
@@ -202,18 +267,65 @@ type CacheUpdatedMsg struct {
 	Cached bool
 }
 
+// MediaDownloadedMsg is sent when a post's files have been downloaded
+type MediaDownloadedMsg struct {
+	PostID    string
+	Results   []download.Result
+	Cancelled bool // The download was stopped before every file was saved
+}
+
+// CommentsFetchedMsg is sent when a page of a post's comments has been loaded
+type CommentsFetchedMsg struct {
+	PostID string
+	Page   *models.CommentsPage // The post's comments so far, including earlier pages
+	Err    error
+}
+
+// CampaignResolvedMsg is sent when the campaign entered in step 1 has been looked up
+type CampaignResolvedMsg struct {
+	Input    string
+	Campaign *models.Campaign
+	Err      error
+}
+
+// MembershipsSyncedMsg is sent when the user's memberships have been imported
+type MembershipsSyncedMsg struct {
+	Result *db.MembershipSync
+	Err    error
+}
+
+// ConfigReloadedMsg is sent when the config file was edited while the app runs. The
+// sender has already given the client any new cookies and reconciled the saved campaigns.
+type ConfigReloadedMsg struct {
+	Config         *config.Config        // The reloaded config; nil if the file couldn't be used
+	CookiesChanged bool                  // The client was given new cookies
+	Campaigns      *db.CampaignReconcile // Campaigns added or removed by the edit
+	Err            error
+}
+
 // CampaignsLoadedMsg is sent when saved campaigns are loaded
 type CampaignsLoadedMsg struct {
 	Campaigns []db.SavedCampaign
 }
 
+// Options configures a new TUI model
+type Options struct {
+	Client         *api.Client
+	Database       *db.Database
+	PublishedAfter string         // Initial date filter (YYYY-MM-DD)
+	DownloadDir    string         // Directory post files are downloaded under
+	Profile        string         // Account profile shown in the title bar, if any
+	Config         *config.Config // Per-campaign settings (default: none)
+	ConfigPath     string         // Config file campaign edits are written back to (default: not written)
+}
+
 // NewModel creates a new TUI model
-func NewModel(cookies string, database *db.Database, publishedAfter string) Model {
+func NewModel(opts Options) Model {
 	ti := textinput.New()
-	ti.Placeholder = "Enter campaign ID (e.g., 2175699)"
+	ti.Placeholder = "2175699, hatfilms or a patreon.com URL"
 	ti.Focus()
-	ti.CharLimit = 20
-	ti.Width = 40
+	ti.CharLimit = 200
+	ti.Width = 50
 
 	ni := textinput.New()
 	ni.Placeholder = "Enter name (optional, press Enter to skip)"
@@ -225,6 +337,11 @@ func NewModel(cookies string, database *db.Database, publishedAfter string) Mode
 	di.CharLimit = 10
 	di.Width = 40
 
+	ci := textinput.New()
+	ci.Placeholder = "session_id=...; patreon_device_id=..."
+	ci.EchoMode = textinput.EchoPassword
+	ci.Width = 50
+
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF424D"))
@@ -233,19 +350,24 @@ func NewModel(cookies string, database *db.Database, publishedAfter string) Mode
 
 	return Model{
 		state:          stateInput,
-		client:         api.NewClient(cookies),
-		database:       database,
+		client:         opts.Client,
+		database:       opts.Database,
+		profile:        opts.Profile,
+		cfg:            opts.Config,
+		cfgPath:        opts.ConfigPath,
 		input:          ti,
 		nameInput:      ni,
 		dateInput:      di,
+		cookieInput:    ci,
 		spinner:        s,
 		viewport:       vp,
 		width:          80,
 		height:         24,
-		clipboardLinks: make([]string, 0),
+		clipboardLinks: make([]models.ProviderLink, 0),
 		cursorHistory:  make([]string, 0),
 		currentPage:    1,
-		publishedAfter: publishedAfter,
+		publishedAfter: opts.PublishedAfter,
+		downloader:     download.New(opts.Client, opts.Database, opts.DownloadDir),
 	}
 }
 
@@ -276,20 +398,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Handle global keys first
 		switch msg.String() {
 		case "ctrl+c", "esc":
+			// Esc stops a campaign lookup or membership import before it quits
+			if msg.String() == "esc" && m.state == stateInput && (m.resolving || m.syncing) {
+				return m.cancelLookups()
+			}
 			// Always allow quit with Ctrl+C or Esc from input screen
 			if m.state == stateInput {
-				return m, tea.Quit
+				return m.quit()
 			}
 		case "q":
 			// Only quit with 'q' if not in input mode
-			if m.state != stateInput {
-				return m, tea.Quit
+			if m.state != stateInput && !m.pastingCookies {
+				return m.quit()
 			}
 		case "c", "y":
 			// Copy clipboard to system clipboard (works in list and details view)
 			if m.state == stateList || m.state == stateDetails {
 				if len(m.clipboardLinks) > 0 {
-					text := strings.Join(m.clipboardLinks, "\n")
+					text := strings.Join(m.clipboardURLs(), "\n")
 					if err := clipboard.WriteAll(text); err == nil {
 						m.statusMessage = fmt.Sprintf("✓ Copied %d links to clipboard!", len(m.clipboardLinks))
 					} else {
@@ -313,7 +439,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "X":
 			// Clear entire clipboard
 			if m.state == stateList || m.state == stateDetails {
-				m.clipboardLinks = make([]string, 0)
+				m.clipboardLinks = make([]models.ProviderLink, 0)
 				m.clipboardCursor = 0
 				m.statusMessage = "Cleared clipboard"
 				return m, nil
@@ -337,6 +463,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case stateInput:
 			return m.handleInputKeys(msg)
 
+		case stateLoading:
+			return m.handleLoadingKeys(msg)
+
 		case stateList:
 			return m.handleListKeys(msg)
 
@@ -359,6 +488,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case PostsFetchedMsg:
+		if msg.seq != m.requestSeq {
+			// Response to a cancelled or superseded request
+			return m, nil
+		}
+		m.finishRequest()
 		if msg.Err != nil {
 			m.state = stateError
 			m.err = msg.Err
@@ -369,19 +503,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.hasMorePages = msg.HasMore
 		m.totalPosts = msg.Total
 
-		// Client-side date filtering
-		if m.publishedAfter != "" {
-			filterDate, err := time.Parse("2006-01-02", m.publishedAfter)
-			if err == nil {
-				var filtered []models.Post
-				for _, post := range m.posts {
-					if post.PublishedAt.After(filterDate) || post.PublishedAt.Equal(filterDate) {
-						filtered = append(filtered, post)
-					}
-				}
-				m.posts = filtered
+		// Client-side date, title and post type filtering
+		m.pastDate = false
+		filterDate, _ := time.Parse("2006-01-02", m.afterDate()) // Zero, matching everything, when unset
+		var filtered []models.Post
+		for _, post := range m.posts {
+			if post.PublishedAt.Before(filterDate) {
+				m.pastDate = true
+				continue
+			}
+			if m.campaign.Filter.Match(post) {
+				filtered = append(filtered, post)
 			}
 		}
+		m.posts = filtered
 
 		// Sort posts by published date (most recent first)
 		sort.Slice(m.posts, func(i, j int) bool {
@@ -402,6 +537,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case PostDetailsFetchedMsg:
+		if msg.seq != m.requestSeq {
+			// Response to a cancelled or superseded request
+			return m, nil
+		}
+		m.finishRequest()
 		if msg.Err != nil {
 			m.state = stateError
 			m.err = msg.Err
@@ -409,10 +549,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.postDetails = msg.Details
 		m.linkCursor = 0
+		m.comments = nil
+		m.commentsLoading = false
 		// Save to cache
 		if m.database != nil && msg.Details != nil {
-			linksJSON, _ := json.Marshal(msg.Details.YouTubeLinks)
-			m.database.SavePostDetails(msg.Details.ID, msg.Details.Description, string(linksJSON))
+			m.database.SavePostDetails(msg.Details)
 			// Update the post's cached status
 			for i := range m.posts {
 				if m.posts[i].ID == msg.Details.ID {
@@ -422,12 +563,136 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		m.state = stateDetails
+		var cmd tea.Cmd
+		if m.showComments {
+			// Keep the pane open across a force refresh
+			cmd = m.loadComments(msg.Details.ID, nil)
+		}
 		m.viewport.SetContent(m.renderDetailsContent())
 		m.viewport.GotoTop()
+		return m, cmd
+
+	case CommentsFetchedMsg:
+		if m.postDetails == nil || m.postDetails.ID != msg.PostID || errors.Is(msg.Err, context.Canceled) {
+			// The user has moved on to another post
+			return m, nil
+		}
+		m.stopComments()
+		if msg.Err != nil {
+			m.statusMessage = fmt.Sprintf("✗ Failed to load comments: %v", msg.Err)
+			return m, nil
+		}
+		m.comments = msg.Page
+		if m.state == stateDetails {
+			m.viewport.SetContent(m.renderDetailsContent())
+		}
 		return m, nil
 
+	case MediaDownloadedMsg:
+		m.downloading = ""
+		if m.cancelDownload != nil {
+			m.cancelDownload()
+			m.cancelDownload = nil
+		}
+		var downloaded, skipped, failed int
+		var firstErr error
+		for _, result := range msg.Results {
+			switch result.Status {
+			case download.StatusDownloaded:
+				downloaded++
+			case download.StatusSkipped:
+				skipped++
+			case download.StatusFailed:
+				failed++
+				if firstErr == nil {
+					firstErr = result.Err
+				}
+			}
+		}
+		if msg.Cancelled {
+			m.statusMessage = fmt.Sprintf("Download cancelled after %d file(s); press d to resume it", downloaded)
+		} else if failed > 0 {
+			m.statusMessage = fmt.Sprintf("✗ %d file(s) failed: %v", failed, firstErr)
+		} else {
+			m.statusMessage = fmt.Sprintf("✓ Downloaded %d file(s), %d already saved", downloaded, skipped)
+		}
+		if m.state == stateDetails && m.postDetails != nil && m.postDetails.ID == msg.PostID {
+			m.viewport.SetContent(m.renderDetailsContent())
+		}
+		return m, nil
+
+	case CampaignResolvedMsg:
+		if !m.resolving || m.inputStep != 1 || msg.Input != m.input.Value() || errors.Is(msg.Err, context.Canceled) {
+			// Abandoned by editing the input
+			return m, nil
+		}
+		m.resolving = false
+		m.cancelResolve = nil
+		if msg.Err != nil {
+			if !isNumeric(msg.Input) {
+				m.resolveErr = msg.Err
+				return m, nil
+			}
+			// A numeric ID can still be used without a name
+			msg.Campaign = &models.Campaign{ID: msg.Input}
+		}
+		// Move to name entry step, prefilled with the creator name
+		m.pendingID = msg.Campaign.ID
+		m.inputStep = 2
+		m.input.Blur()
+		m.nameInput.SetValue(msg.Campaign.Name)
+		m.nameInput.CursorEnd()
+		m.nameInput.Focus()
+		return m, textinput.Blink
+
+	case MembershipsSyncedMsg:
+		m.syncing = false
+		if m.cancelSync != nil {
+			m.cancelSync()
+			m.cancelSync = nil
+		}
+		if errors.Is(msg.Err, context.Canceled) {
+			m.statusMessage = "Membership import cancelled"
+			return m, nil
+		}
+		if msg.Err != nil {
+			m.statusMessage = fmt.Sprintf("✗ Failed to sync memberships: %v", msg.Err)
+			return m, nil
+		}
+		m.statusMessage = fmt.Sprintf("✓ Synced %d memberships: %d new, %d lapsed",
+			msg.Result.Total, len(msg.Result.Added), len(msg.Result.Lapsed))
+		if m.inputStep != 0 && len(m.savedCampaigns) > 0 {
+			// Don't interrupt adding a campaign; the list is reloaded on the way back
+			return m, nil
+		}
+		return m, m.loadCampaigns()
+
+	case ConfigReloadedMsg:
+		if msg.Config == nil {
+			m.statusMessage = fmt.Sprintf("✗ Config not reloaded: %v", msg.Err)
+			return m, nil
+		}
+		m.cfg = msg.Config
+		if !m.filterEdited {
+			m.publishedAfter = msg.Config.PublishedAfter
+		}
+		// The open campaign keeps its settings until it's opened again, so its pages stay consistent
+		if dir := msg.Config.GetDownloadDir(); dir != m.downloader.Root() && m.downloading == "" {
+			m.downloader = download.New(m.client, m.database, dir)
+		}
+		m.statusMessage = reloadSummary(msg)
+		if m.state != stateInput || (m.inputStep != 0 && len(m.savedCampaigns) > 0) {
+			// Don't interrupt what the user is doing; the list is reloaded on the way back
+			return m, nil
+		}
+		return m, m.loadCampaigns()
+
 	case CampaignsLoadedMsg:
 		m.savedCampaigns = msg.Campaigns
+		if m.campaignCursor >= len(m.savedCampaigns) {
+			// Campaigns can be removed from under the cursor by a config reload
+			m.campaignCursor = max(len(m.savedCampaigns)-1, 0)
+		}
 		// Start in ID input mode if no saved campaigns, otherwise selection mode
 		if len(m.savedCampaigns) == 0 {
 			m.inputStep = 1
@@ -453,6 +718,140 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// startLoading switches to the loading view and returns the context for the request it waits on.
+// Any request still in flight is cancelled, and the current view is remembered so Esc can restore it.
+func (m *Model) startLoading(msg string) context.Context {
+	if m.cancelRequest != nil {
+		m.cancelRequest()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelRequest = cancel
+	m.requestSeq++
+	m.loadingReturn = m.state
+	m.returnPage = m.currentPage
+	m.returnHistory = append([]string(nil), m.cursorHistory...)
+	m.state = stateLoading
+	m.loadingMsg = msg
+	return ctx
+}
+
+// finishRequest releases the context of the request that just completed
+func (m *Model) finishRequest() {
+	if m.cancelRequest != nil {
+		m.cancelRequest()
+		m.cancelRequest = nil
+	}
+}
+
+// resolveCampaign looks up the campaign for an ID, creator name or Patreon URL
+func (m Model) resolveCampaign(ctx context.Context, input string) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		campaign, err := client.ResolveCampaignContext(ctx, input)
+		return CampaignResolvedMsg{Input: input, Campaign: campaign, Err: err}
+	}
+}
+
+// syncMemberships imports the campaigns the user is a member of into the database
+func (m Model) syncMemberships(ctx context.Context) tea.Cmd {
+	client, database := m.client, m.database
+	var deleted []string
+	if m.cfg != nil {
+		for _, id := range m.cfg.DeletedCampaigns {
+			if m.cfg.IsDeleted(id) {
+				deleted = append(deleted, id)
+			}
+		}
+	}
+	return func() tea.Msg {
+		fetched, err := client.FetchMembershipsContext(ctx)
+		if err != nil {
+			return MembershipsSyncedMsg{Err: err}
+		}
+		// Campaigns deleted in the TUI stay deleted
+		var memberships []models.Membership
+		for _, membership := range fetched {
+			if !slices.Contains(deleted, membership.Campaign.ID) {
+				memberships = append(memberships, membership)
+			}
+		}
+		result, err := database.SyncMemberships(memberships)
+		return MembershipsSyncedMsg{Result: result, Err: err}
+	}
+}
+
+// startSync begins importing memberships unless an import is already running
+func (m Model) startSync() (tea.Model, tea.Cmd) {
+	if m.syncing || m.database == nil {
+		return m, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.syncing = true
+	m.cancelSync = cancel
+	return m, tea.Batch(m.spinner.Tick, m.syncMemberships(ctx))
+}
+
+// cancelLookups stops the campaign lookup and membership import in progress. The import
+// reports its cancellation when it stops.
+func (m Model) cancelLookups() (tea.Model, tea.Cmd) {
+	if m.resolving {
+		m.cancelResolve()
+		m.cancelResolve = nil
+		m.resolving = false
+	}
+	if m.cancelSync != nil {
+		m.cancelSync()
+		m.cancelSync = nil
+	}
+	m.statusMessage = "Cancelled"
+	return m, nil
+}
+
+// quit stops everything running in the background and quits. Downloads stopped this way
+// resume from their .part files next time.
+func (m Model) quit() (tea.Model, tea.Cmd) {
+	for _, cancel := range []context.CancelFunc{m.cancelRequest, m.cancelResolve, m.cancelSync, m.cancelComments, m.cancelDownload} {
+		if cancel != nil {
+			cancel()
+		}
+	}
+	return m, tea.Quit
+}
+
+// isNumeric reports whether s is a non-empty string of digits, like a campaign ID
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Model) handleLoadingKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		// Abort the in-flight request and go back to where we came from
+		m.finishRequest()
+		m.requestSeq++
+		m.currentPage = m.returnPage
+		m.cursorHistory = m.returnHistory
+		m.state = m.loadingReturn
+		m.statusMessage = "Cancelled"
+		if m.state == stateInput {
+			m.inputStep = 0
+			return m, m.loadCampaigns()
+		}
+		if m.state == stateDetails {
+			m.viewport.SetContent(m.renderDetailsContent())
+		}
+	}
+	return m, nil
+}
+
 func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "up", "k":
@@ -471,15 +870,9 @@ func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				cached, err := m.database.GetPost(post.ID)
 				if err == nil && cached != nil && cached.DetailsCached {
 					m.cachedDetails = cached
-					m.postDetails = &models.PostDetails{
-						ID:          cached.ID,
-						Title:       cached.Title,
-						Description: cached.Description,
-					}
-					if cached.YouTubeLinks != "" {
-						json.Unmarshal([]byte(cached.YouTubeLinks), &m.postDetails.YouTubeLinks)
-					}
+					m.postDetails = m.applyLinks(cached.Details())
 					m.linkCursor = 0
+					m.comments = nil
 					m.state = stateDetails
 					m.viewport.SetContent(m.renderDetailsContent())
 					m.viewport.GotoTop()
@@ -487,52 +880,44 @@ func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				}
 			}
 			// Fetch from API
-			m.state = stateLoading
-			m.loadingMsg = "Fetching post details..."
-			return m, tea.Batch(m.spinner.Tick, m.fetchPostDetails(post.ID))
+			ctx := m.startLoading("Fetching post details...")
+			return m, tea.Batch(m.spinner.Tick, m.fetchPostDetails(ctx, post.ID))
 		}
 	case "r":
 		// Refresh current page (from cache if available)
-		m.state = stateLoading
-		m.loadingMsg = "Refreshing posts..."
+		ctx := m.startLoading("Refreshing posts...")
 		// Get the cursor for the current page (empty for page 1, last history item otherwise)
 		cursor := ""
 		if m.currentPage > 1 && len(m.cursorHistory) > 0 {
 			cursor = m.cursorHistory[len(m.cursorHistory)-1]
 		}
-		return m, tea.Batch(m.spinner.Tick, m.fetchPosts(cursor, false))
+		return m, tea.Batch(m.spinner.Tick, m.fetchPosts(ctx, cursor, false))
 	case "R":
 		// Force refresh - clear cache and go back to page 1
+		ctx := m.startLoading("Force refreshing posts...")
 		if m.database != nil {
 			m.database.ClearCampaignPages(m.campaignID)
 		}
 		m.currentPage = 1
 		m.cursorHistory = make([]string, 0)
-		m.state = stateLoading
-		m.loadingMsg = "Force refreshing posts..."
-		return m, tea.Batch(m.spinner.Tick, m.fetchPosts("", true))
+		return m, tea.Batch(m.spinner.Tick, m.fetchPosts(ctx, "", true))
 	case "n", "l", "right":
 		// Next page
-		// Don't allow next page if filter is active and we have fewer posts than page size
-		// (indicates the filter removed posts, not that there are no more pages)
-		canGoNext := m.hasMorePages && m.nextCursor != ""
-		if m.publishedAfter != "" && len(m.posts) < 20 {
-			canGoNext = false
-		}
-		if canGoNext {
+		// Don't allow next page once the date filter removed posts, since later pages are older still
+		if m.hasMorePages && m.nextCursor != "" && !m.pastDate {
+			ctx := m.startLoading(fmt.Sprintf("Loading page %d...", m.currentPage+1))
 			// Save current cursor to history for going back
 			if m.currentPage == 1 {
 				m.cursorHistory = append(m.cursorHistory, "")
 			}
 			m.cursorHistory = append(m.cursorHistory, m.nextCursor)
 			m.currentPage++
-			m.state = stateLoading
-			m.loadingMsg = fmt.Sprintf("Loading page %d...", m.currentPage)
-			return m, tea.Batch(m.spinner.Tick, m.fetchPosts(m.nextCursor, false))
+			return m, tea.Batch(m.spinner.Tick, m.fetchPosts(ctx, m.nextCursor, false))
 		}
 	case "p", "h", "left":
 		// Previous page
 		if m.currentPage > 1 && len(m.cursorHistory) > 0 {
+			ctx := m.startLoading(fmt.Sprintf("Loading page %d...", m.currentPage-1))
 			m.currentPage--
 			// Pop the current cursor from history
 			m.cursorHistory = m.cursorHistory[:len(m.cursorHistory)-1]
@@ -541,9 +926,7 @@ func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if len(m.cursorHistory) > 0 {
 				cursor = m.cursorHistory[len(m.cursorHistory)-1]
 			}
-			m.state = stateLoading
-			m.loadingMsg = fmt.Sprintf("Loading page %d...", m.currentPage)
-			return m, tea.Batch(m.spinner.Tick, m.fetchPosts(cursor, false))
+			return m, tea.Batch(m.spinner.Tick, m.fetchPosts(ctx, cursor, false))
 		}
 	case "esc":
 		m.state = stateInput
@@ -561,6 +944,9 @@ func (m Model) handleDetailsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.postDetails = nil
 		m.cachedDetails = nil
 		m.linkCursor = 0
+		m.showComments = false
+		m.comments = nil
+		m.stopComments()
 		return m, nil
 	case "R":
 		// Force refresh this post's details
@@ -568,62 +954,98 @@ func (m Model) handleDetailsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			post := m.posts[m.cursor]
 			if m.database != nil {
 				m.database.ClearPostDetails(post.ID)
+				m.database.ClearComments(post.ID)
 				post.DetailsCached = false
 				m.posts[m.cursor] = post
 			}
-			m.state = stateLoading
-			m.loadingMsg = "Force refreshing post details..."
-			return m, tea.Batch(m.spinner.Tick, m.fetchPostDetails(post.ID))
+			ctx := m.startLoading("Force refreshing post details...")
+			return m, tea.Batch(m.spinner.Tick, m.fetchPostDetails(ctx, post.ID))
 		}
 	case "up", "k":
-		// Navigate YouTube links
-		if m.postDetails != nil && len(m.postDetails.YouTubeLinks) > 0 && m.linkCursor > 0 {
+		// Navigate links
+		if m.linkCursor > 0 {
 			m.linkCursor--
 			m.viewport.SetContent(m.renderDetailsContent())
 		}
 	case "down", "j":
-		// Navigate YouTube links
-		if m.postDetails != nil && len(m.postDetails.YouTubeLinks) > 0 && m.linkCursor < len(m.postDetails.YouTubeLinks)-1 {
+		// Navigate links
+		if m.linkCursor < len(m.selectableLinks())-1 {
 			m.linkCursor++
 			m.viewport.SetContent(m.renderDetailsContent())
 		}
 	case "a", "enter":
-		// Add selected YouTube link to clipboard
-		if m.postDetails != nil && len(m.postDetails.YouTubeLinks) > 0 {
-			link := m.postDetails.YouTubeLinks[m.linkCursor]
-			// Check if already in clipboard
-			for _, existing := range m.clipboardLinks {
-				if existing == link {
-					m.statusMessage = "Link already in clipboard"
-					return m, nil
-				}
+		// Add selected link to clipboard
+		if selectable := m.selectableLinks(); len(selectable) > 0 {
+			if !m.addToClipboard(selectable[m.linkCursor]) {
+				m.statusMessage = "Link already in clipboard"
+				return m, nil
 			}
-			m.clipboardLinks = append(m.clipboardLinks, link)
 			m.statusMessage = "✓ Added link to clipboard"
+			m.viewport.SetContent(m.renderDetailsContent())
 		}
 	case "A":
-		// Add ALL YouTube links to clipboard
-		if m.postDetails != nil && len(m.postDetails.YouTubeLinks) > 0 {
+		// Add ALL provider links to clipboard
+		if m.postDetails != nil && len(m.postDetails.ProviderLinks) > 0 {
 			added := 0
-			for _, link := range m.postDetails.YouTubeLinks {
-				exists := false
-				for _, existing := range m.clipboardLinks {
-					if existing == link {
-						exists = true
-						break
-					}
-				}
-				if !exists {
-					m.clipboardLinks = append(m.clipboardLinks, link)
+			for _, link := range m.postDetails.ProviderLinks {
+				if m.addToClipboard(link) {
 					added++
 				}
 			}
 			if added > 0 {
 				m.statusMessage = fmt.Sprintf("✓ Added %d links to clipboard", added)
+				m.viewport.SetContent(m.renderDetailsContent())
 			} else {
 				m.statusMessage = "All links already in clipboard"
 			}
 		}
+	case "C":
+		// Toggle the comments pane, loading the first page if needed
+		if m.postDetails == nil {
+			return m, nil
+		}
+		m.showComments = !m.showComments
+		if !m.showComments {
+			// Comment links are no longer selectable
+			if m.linkCursor >= len(m.selectableLinks()) {
+				m.linkCursor = max(len(m.selectableLinks())-1, 0)
+			}
+			m.viewport.SetContent(m.renderDetailsContent())
+			return m, nil
+		}
+		if m.comments == nil && !m.commentsLoading {
+			cmd := m.loadComments(m.postDetails.ID, nil)
+			m.viewport.SetContent(m.renderDetailsContent())
+			return m, cmd
+		}
+		m.viewport.SetContent(m.renderDetailsContent())
+	case "M":
+		// Load the next page of comments
+		if m.showComments && m.comments != nil && m.comments.HasMore && !m.commentsLoading {
+			cmd := m.loadComments(m.postDetails.ID, m.comments)
+			m.viewport.SetContent(m.renderDetailsContent())
+			return m, cmd
+		}
+	case "d":
+		// Download the post's attachments, images and audio in the background
+		if m.postDetails == nil || len(m.postDetails.Media) == 0 {
+			m.statusMessage = "No files to download"
+			return m, nil
+		}
+		if m.downloading != "" {
+			// Pressing d again stops the download; its .part files let it resume later
+			if m.cancelDownload != nil {
+				m.cancelDownload()
+				m.cancelDownload = nil
+				m.statusMessage = "Cancelling download of " + m.downloading + "..."
+			}
+			return m, nil
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		m.downloading = m.postDetails.Title
+		m.cancelDownload = cancel
+		m.statusMessage = fmt.Sprintf("⬇ Downloading %d file(s)... press d again to cancel", len(m.postDetails.Media))
+		return m, m.downloadMedia(ctx, m.postDetails)
 	case "pgup":
 		m.viewport.HalfViewUp()
 	case "pgdown":
@@ -632,17 +1054,127 @@ func (m Model) handleDetailsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// loadComments starts fetching the page of comments after loaded, or the first page if
+// loaded is nil, cancelling any page still being fetched
+func (m *Model) loadComments(postID string, loaded *models.CommentsPage) tea.Cmd {
+	m.stopComments()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.commentsLoading = true
+	m.cancelComments = cancel
+	return m.fetchComments(ctx, postID, loaded)
+}
+
+// stopComments cancels the page of comments being fetched, if any
+func (m *Model) stopComments() {
+	if m.cancelComments != nil {
+		m.cancelComments()
+		m.cancelComments = nil
+	}
+	m.commentsLoading = false
+}
+
+// fetchComments loads the page of comments after loaded, or the first page if loaded is nil.
+// The first page is served from the cache when available.
+func (m Model) fetchComments(ctx context.Context, postID string, loaded *models.CommentsPage) tea.Cmd {
+	client, database := m.client, m.database
+	return func() tea.Msg {
+		if loaded == nil && database != nil {
+			if cached, err := database.GetComments(postID); err == nil && cached != nil {
+				return CommentsFetchedMsg{PostID: postID, Page: cached}
+			}
+		}
+
+		cursor := ""
+		if loaded != nil {
+			cursor = loaded.NextCursor
+		}
+		page, err := client.FetchCommentsContext(ctx, postID, cursor)
+		if err != nil {
+			return CommentsFetchedMsg{PostID: postID, Err: err}
+		}
+		if database != nil {
+			database.SaveComments(postID, page)
+		}
+
+		if loaded != nil {
+			page.Comments = append(append([]models.Comment{}, loaded.Comments...), page.Comments...)
+		}
+		return CommentsFetchedMsg{PostID: postID, Page: page}
+	}
+}
+
+// commentLinks lists the links found in loaded comments, in display order
+func (m Model) commentLinks() []models.ProviderLink {
+	if !m.showComments || m.comments == nil {
+		return nil
+	}
+	var result []models.ProviderLink
+	var walk func(comments []models.Comment)
+	walk = func(comments []models.Comment) {
+		for _, comment := range comments {
+			result = append(result, comment.Links...)
+			walk(comment.Replies)
+		}
+	}
+	walk(m.comments.Comments)
+	return result
+}
+
+// downloadMedia saves a post's files under the current campaign's directory until ctx is cancelled
+func (m Model) downloadMedia(ctx context.Context, details *models.PostDetails) tea.Cmd {
+	downloader := m.downloader
+	campaignID, campaignName := m.campaignID, m.campaignName
+	return func() tea.Msg {
+		results := downloader.DownloadPost(ctx, campaignID, campaignName, details)
+		return MediaDownloadedMsg{PostID: details.ID, Results: results, Cancelled: ctx.Err() != nil}
+	}
+}
+
 func (m Model) handleErrorKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.pastingCookies {
+		switch msg.String() {
+		case "enter":
+			if m.cookieInput.Value() == "" {
+				return m, nil
+			}
+			m.client.SetCookies(m.cookieInput.Value())
+			m.cookieInput.SetValue("")
+			m.cookieInput.Blur()
+			m.pastingCookies = false
+			ctx := m.startLoading("Retrying with new cookies...")
+			cursor := ""
+			if m.currentPage > 1 && len(m.cursorHistory) > 0 {
+				cursor = m.cursorHistory[len(m.cursorHistory)-1]
+			}
+			return m, tea.Batch(m.spinner.Tick, m.fetchPosts(ctx, cursor, true))
+		case "esc":
+			m.cookieInput.SetValue("")
+			m.cookieInput.Blur()
+			m.pastingCookies = false
+			return m, nil
+		default:
+			var cmd tea.Cmd
+			m.cookieInput, cmd = m.cookieInput.Update(msg)
+			return m, cmd
+		}
+	}
+
 	switch msg.String() {
+	case "p":
+		// Paste new cookies when the session has expired
+		if errors.Is(m.err, api.ErrUnauthorized) {
+			m.pastingCookies = true
+			m.cookieInput.Focus()
+			return m, textinput.Blink
+		}
 	case "r":
-		m.state = stateLoading
-		m.loadingMsg = "Retrying..."
+		ctx := m.startLoading("Retrying...")
 		// Retry with current page's cursor (bypass cache on retry)
 		cursor := ""
 		if m.currentPage > 1 && len(m.cursorHistory) > 0 {
 			cursor = m.cursorHistory[len(m.cursorHistory)-1]
 		}
-		return m, tea.Batch(m.spinner.Tick, m.fetchPosts(cursor, true))
+		return m, tea.Batch(m.spinner.Tick, m.fetchPosts(ctx, cursor, true))
 	case "esc":
 		m.state = stateInput
 		m.input.SetValue("")
@@ -654,18 +1186,20 @@ func (m Model) handleErrorKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m Model) handleInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch m.inputStep {
-	case 1: // Entering campaign ID
+	case 1: // Entering campaign ID, creator name or URL
 		switch msg.String() {
 		case "enter":
-			if m.input.Value() != "" {
-				// Move to name entry step
-				m.pendingID = m.input.Value()
-				m.inputStep = 2
-				m.input.Blur()
-				m.nameInput.SetValue("")
-				m.nameInput.Focus()
-				return m, textinput.Blink
+			if m.input.Value() != "" && !m.resolving {
+				// Look up the campaign ID and creator name
+				ctx, cancel := context.WithCancel(context.Background())
+				m.resolving = true
+				m.cancelResolve = cancel
+				m.resolveErr = nil
+				return m, tea.Batch(m.spinner.Tick, m.resolveCampaign(ctx, m.input.Value()))
 			}
+		case "ctrl+s":
+			// The selection list is skipped when nothing is saved, so offer the sync here too
+			return m.startSync()
 		case "esc":
 			// If we have saved campaigns, go back to selection mode
 			if len(m.savedCampaigns) > 0 {
@@ -675,10 +1209,17 @@ func (m Model) handleInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, m.loadCampaigns()
 			}
 			// Otherwise quit
-			return m, tea.Quit
+			return m.quit()
 		default:
 			var cmd tea.Cmd
 			m.input, cmd = m.input.Update(msg)
+			// Editing the input abandons any lookup in progress
+			if m.resolving {
+				m.cancelResolve()
+				m.cancelResolve = nil
+				m.resolving = false
+			}
+			m.resolveErr = nil
 			return m, cmd
 		}
 		return m, nil
@@ -686,6 +1227,15 @@ func (m Model) handleInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case 2: // Entering campaign name
 		switch msg.String() {
 		case "enter":
+			if m.renaming {
+				// Renaming from the selection screen, go back to it
+				m.renaming = false
+				m.inputStep = 0
+				m.nameInput.Blur()
+				m.statusMessage = m.saveCampaign(m.pendingID, m.nameInput.Value())
+				m.pendingID = ""
+				return m, m.loadCampaigns()
+			}
 			// Move to date filter step
 			m.campaignName = m.nameInput.Value()
 			m.inputStep = 3
@@ -694,6 +1244,13 @@ func (m Model) handleInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.dateInput.Focus()
 			return m, textinput.Blink
 		case "esc":
+			if m.renaming {
+				m.renaming = false
+				m.inputStep = 0
+				m.nameInput.Blur()
+				m.pendingID = ""
+				return m, nil
+			}
 			// Go back to ID entry
 			m.inputStep = 1
 			m.nameInput.Blur()
@@ -709,6 +1266,7 @@ func (m Model) handleInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "enter":
 			m.publishedAfter = m.dateInput.Value()
+			m.filterEdited = true
 			m.dateInput.Blur()
 
 			if m.editingDateOnly {
@@ -720,15 +1278,13 @@ func (m Model) handleInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 			// Adding a new campaign - save it and fetch posts
 			m.campaignID = m.pendingID
-			if m.database != nil {
-				m.database.SaveCampaign(m.campaignID, m.campaignName)
-			}
+			m.campaign = m.campaignSettings(m.campaignID)
+			m.statusMessage = m.saveCampaign(m.campaignID, m.campaignName)
+			ctx := m.startLoading("Fetching posts...")
 			m.currentPage = 1
 			m.cursorHistory = make([]string, 0)
-			m.state = stateLoading
-			m.loadingMsg = "Fetching posts..."
 			m.pendingID = ""
-			return m, tea.Batch(m.spinner.Tick, m.fetchPosts("", false))
+			return m, tea.Batch(m.spinner.Tick, m.fetchPosts(ctx, "", false))
 		case "esc":
 			if m.editingDateOnly {
 				// Go back to selection mode
@@ -763,11 +1319,11 @@ func (m Model) handleInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				selected := m.savedCampaigns[m.campaignCursor]
 				m.campaignID = selected.ID
 				m.campaignName = selected.Name
+				m.campaign = m.campaignSettings(selected.ID)
+				ctx := m.startLoading("Fetching posts...")
 				m.currentPage = 1
 				m.cursorHistory = make([]string, 0)
-				m.state = stateLoading
-				m.loadingMsg = "Fetching posts..."
-				return m, tea.Batch(m.spinner.Tick, m.fetchPosts("", false))
+				return m, tea.Batch(m.spinner.Tick, m.fetchPosts(ctx, "", false))
 			}
 		case "n", "a":
 			// Switch to input mode to add new campaign
@@ -782,9 +1338,25 @@ func (m Model) handleInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				if m.database != nil {
 					m.database.DeleteCampaign(selected.ID)
 				}
+				m.statusMessage = m.writeCampaigns(func(cfg *config.Config) { cfg.RemoveCampaign(selected.ID) })
 				// Reload campaigns
 				return m, m.loadCampaigns()
 			}
+		case "e":
+			// Rename selected campaign
+			if len(m.savedCampaigns) > 0 {
+				selected := m.savedCampaigns[m.campaignCursor]
+				m.pendingID = selected.ID
+				m.renaming = true
+				m.inputStep = 2
+				m.nameInput.SetValue(selected.Name)
+				m.nameInput.CursorEnd()
+				m.nameInput.Focus()
+				return m, textinput.Blink
+			}
+		case "s":
+			// Import campaigns from the user's memberships
+			return m.startSync()
 		case "f":
 			// Edit date filter
 			m.inputStep = 3
@@ -795,7 +1367,7 @@ func (m Model) handleInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Clipboard operations
 		case "c", "y":
 			if len(m.clipboardLinks) > 0 {
-				allLinks := strings.Join(m.clipboardLinks, "\n")
+				allLinks := strings.Join(m.clipboardURLs(), "\n")
 				clipboard.WriteAll(allLinks)
 				m.statusMessage = fmt.Sprintf("Copied %d links", len(m.clipboardLinks))
 			}
@@ -809,7 +1381,7 @@ func (m Model) handleInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		case "X":
 			// Clear entire clipboard
-			m.clipboardLinks = make([]string, 0)
+			m.clipboardLinks = make([]models.ProviderLink, 0)
 			m.clipboardCursor = 0
 		case "[":
 			// Navigate clipboard up
@@ -822,13 +1394,53 @@ func (m Model) handleInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.clipboardCursor++
 			}
 		case "esc", "ctrl+c":
-			return m, tea.Quit
+			return m.quit()
 		}
 	}
 	return m, nil
 }
 
-func (m Model) fetchPosts(cursor string, forceRefresh bool) tea.Cmd {
+// selectableLinks returns the links the details view cursor moves over:
+// provider links first, then every hyperlink in the post, then links in shown comments
+func (m Model) selectableLinks() []models.ProviderLink {
+	if m.postDetails == nil {
+		return nil
+	}
+	selectable := append([]models.ProviderLink(nil), m.postDetails.ProviderLinks...)
+	for _, link := range m.postDetails.Links {
+		selectable = append(selectable, models.ProviderLink{URL: link.URL})
+	}
+	return append(selectable, m.commentLinks()...)
+}
+
+// clipboardURLs returns the URLs in the clipboard in display order
+func (m Model) clipboardURLs() []string {
+	urls := make([]string, len(m.clipboardLinks))
+	for i, link := range m.clipboardLinks {
+		urls[i] = link.URL
+	}
+	return urls
+}
+
+// inClipboard reports whether a URL has already been collected
+func (m Model) inClipboard(url string) bool {
+	for _, existing := range m.clipboardLinks {
+		if existing.URL == url {
+			return true
+		}
+	}
+	return false
+}
+
+// addToClipboard inserts a link at the end of its provider group, returning false if it is already present
+func (m *Model) addToClipboard(link models.ProviderLink) bool {
+	var added bool
+	m.clipboardLinks, added = links.Insert(m.clipboardLinks, link)
+	return added
+}
+
+func (m Model) fetchPosts(ctx context.Context, cursor string, forceRefresh bool) tea.Cmd {
+	seq := m.requestSeq
 	return func() tea.Msg {
 		// Check cache first (unless force refresh)
 		if !forceRefresh && m.database != nil {
@@ -843,15 +1455,16 @@ func (m Model) fetchPosts(cursor string, forceRefresh bool) tea.Cmd {
 						HasMore:    cachedPage.HasMore,
 						Total:      0,
 						FromCache:  true,
+						seq:        seq,
 					}
 				}
 			}
 		}
 
 		// Fetch from API
-		page, err := m.client.FetchPosts(m.campaignID, 20, cursor)
+		page, err := m.campaignClient().FetchPostsContext(ctx, m.campaignID, m.pageSize(), cursor)
 		if err != nil {
-			return PostsFetchedMsg{Err: err}
+			return PostsFetchedMsg{Err: err, seq: seq}
 		}
 
 		// Save campaign and posts to cache
@@ -884,15 +1497,98 @@ func (m Model) fetchPosts(cursor string, forceRefresh bool) tea.Cmd {
 			HasMore:    page.HasMore,
 			Total:      page.Total,
 			FromCache:  false,
+			seq:        seq,
 		}
 	}
 }
 
-func (m Model) fetchPostDetails(postID string) tea.Cmd {
+func (m Model) fetchPostDetails(ctx context.Context, postID string) tea.Cmd {
+	seq := m.requestSeq
 	return func() tea.Msg {
-		details, err := m.client.FetchPostDetails(postID)
-		return PostDetailsFetchedMsg{Details: details, Err: err}
+		details, err := m.campaignClient().FetchPostDetailsContext(ctx, postID)
+		if err == nil {
+			details = m.applyLinks(details)
+		}
+		return PostDetailsFetchedMsg{Details: details, Err: err, seq: seq}
+	}
+}
+
+// saveCampaign saves an added or renamed campaign to the database and the config file,
+// returning a status message if the config file couldn't be written
+func (m Model) saveCampaign(id, name string) string {
+	if m.database != nil {
+		m.database.SaveCampaign(id, name)
+	}
+	return m.writeCampaigns(func(cfg *config.Config) { cfg.AddCampaign(id, name) })
+}
+
+// writeCampaigns applies a campaign edit to the config and writes the campaign list back to
+// the config file, so the edit survives the file's list being loaded at the next start. It
+// returns a status message if the file couldn't be written.
+func (m Model) writeCampaigns(edit func(*config.Config)) string {
+	if m.cfg == nil || m.cfgPath == "" {
+		return ""
+	}
+	edit(m.cfg)
+	if err := config.SaveCampaigns(m.cfgPath, m.cfg); err != nil {
+		return fmt.Sprintf("✗ Failed to update %s: %v", m.cfgPath, err)
+	}
+	if m.database != nil {
+		// Mark the campaigns as coming from the config file
+		entries := make([]db.CampaignEntry, len(m.cfg.Campaigns))
+		for i, campaign := range m.cfg.Campaigns {
+			entries[i] = db.CampaignEntry{ID: campaign.ID, Name: campaign.Name}
+		}
+		if _, err := m.database.ReconcileCampaigns(entries, m.cfg.DeletedCampaigns); err != nil {
+			return fmt.Sprintf("✗ Failed to save campaigns: %v", err)
+		}
+	}
+	return ""
+}
+
+// campaignSettings returns the config's settings for a campaign, or none without a config
+func (m Model) campaignSettings(id string) config.CampaignSettings {
+	if m.cfg == nil {
+		return config.CampaignSettings{}
 	}
+	return m.cfg.CampaignSettings(id)
+}
+
+// afterDate returns the open campaign's date filter: its own published_after, unless the
+// filter has been changed in the TUI
+func (m Model) afterDate() string {
+	if m.campaign.Overridden("published_after") && !m.filterEdited {
+		return m.campaign.PublishedAfter
+	}
+	return m.publishedAfter
+}
+
+// pageSize returns the number of posts fetched per page for the open campaign
+func (m Model) pageSize() int {
+	if m.campaign.PageSize > 0 {
+		return m.campaign.PageSize
+	}
+	return 20
+}
+
+// campaignClient returns the client with the open campaign's request delays, if it overrides them
+func (m Model) campaignClient() *api.Client {
+	if !m.campaign.Overridden("request_delays") {
+		return m.client
+	}
+	return m.client.WithRateLimit(api.RateLimit{
+		MinDelay: time.Duration(m.campaign.RequestDelayMinMs) * time.Millisecond,
+		MaxDelay: time.Duration(m.campaign.RequestDelayMaxMs) * time.Millisecond,
+		Burst:    m.cfg.GetRequestBurst(),
+	})
+}
+
+// applyLinks re-extracts provider links with the open campaign's providers, if it changes them
+func (m Model) applyLinks(details *models.PostDetails) *models.PostDetails {
+	if m.campaign.LinkProviders != nil {
+		links.NewDefaultRegistry(m.campaign.LinkProviders).Apply(details)
+	}
+	return details
 }
 
 // renderClipboardPanel renders the right-side clipboard panel
@@ -932,8 +1628,12 @@ func (m Model) renderClipboardPanel(height int, topPadding int) string {
 
 		for i := start; i < end; i++ {
 			link := m.clipboardLinks[i]
-			// Extract video ID for display
-			displayLink := link
+			// Provider heading at the start of each group
+			if i == start || m.clipboardLinks[i-1].Provider != link.Provider {
+				b.WriteString(providerHeading(link.Provider))
+				b.WriteString("\n")
+			}
+			displayLink := link.URL
 			if len(displayLink) > clipboardPanelWidth-8 {
 				displayLink = displayLink[:clipboardPanelWidth-11] + "..."
 			}
@@ -992,14 +1692,20 @@ func (m Model) viewInput() string {
 	}
 	var b strings.Builder
 
-	b.WriteString(titleStyle.Render("🎨 Patreon Posts Viewer"))
+	b.WriteString(m.renderTitle())
 	b.WriteString("\n\n")
 
 	switch m.inputStep {
-	case 1: // Entering campaign ID
-		b.WriteString("Enter a new campaign ID:\n\n")
+	case 1: // Entering campaign ID, creator name or URL
+		b.WriteString("Enter a campaign ID, creator name or Patreon URL:\n\n")
 		b.WriteString(inputStyle.Render(m.input.View()))
 		b.WriteString("\n\n")
+		if m.resolving {
+			b.WriteString(fmt.Sprintf("%s Looking up campaign...\n\n", m.spinner.View()))
+		} else if m.resolveErr != nil {
+			b.WriteString(errorStyle.Render(fmt.Sprintf("Could not find campaign: %v", m.resolveErr)))
+			b.WriteString("\n\n")
+		}
 		if len(m.savedCampaigns) > 0 {
 			b.WriteString(helpStyle.Render("Enter to continue • Esc back to list • Ctrl+C quit"))
 		} else {
@@ -1008,10 +1714,18 @@ func (m Model) viewInput() string {
 
 	case 2: // Entering campaign name
 		b.WriteString(fmt.Sprintf("Campaign ID: %s\n\n", m.pendingID))
-		b.WriteString("Enter a name for this campaign (optional):\n\n")
+		if m.renaming {
+			b.WriteString("Rename this campaign:\n\n")
+		} else {
+			b.WriteString("Enter a name for this campaign (optional):\n\n")
+		}
 		b.WriteString(inputStyle.Render(m.nameInput.View()))
 		b.WriteString("\n\n")
-		b.WriteString(helpStyle.Render("Enter to continue • Esc back to ID entry"))
+		if m.renaming {
+			b.WriteString(helpStyle.Render("Enter to save • Esc back to list"))
+		} else {
+			b.WriteString(helpStyle.Render("Enter to continue • Esc back to ID entry"))
+		}
 
 	case 3: // Entering date filter
 		if m.editingDateOnly {
@@ -1043,6 +1757,9 @@ func (m Model) viewInput() string {
 				if campaign.Name != "" {
 					displayName = fmt.Sprintf("%s (%s)", campaign.Name, campaign.ID)
 				}
+				if membership := membershipSummary(campaign); membership != "" {
+					displayName += " · " + membership
+				}
 
 				if i == m.campaignCursor {
 					b.WriteString(selectedStyle.Render(fmt.Sprintf(" ▶ %s ", displayName)))
@@ -1057,17 +1774,19 @@ func (m Model) viewInput() string {
 			if m.publishedAfter != "" {
 				b.WriteString(fmt.Sprintf("📅 Filter: posts after %s\n\n", m.publishedAfter))
 			}
-			helpText := "↑/k ↓/j nav • Enter select • n/a new • f filter • d delete • Esc quit"
+			b.WriteString(m.viewSyncStatus())
+			helpText := "↑/k ↓/j nav • Enter select • n/a new • s sync memberships • f filter • e rename • d delete • Esc quit"
 			if len(m.clipboardLinks) > 0 {
 				helpText += "\nc copy • x remove • X clear"
 			}
 			b.WriteString(helpStyle.Render(helpText))
 		} else {
 			b.WriteString("No saved campaigns.\n\n")
-			b.WriteString("Enter a campaign ID:\n\n")
+			b.WriteString("Enter a campaign ID, creator name or Patreon URL:\n\n")
 			b.WriteString(inputStyle.Render(m.input.View()))
 			b.WriteString("\n\n")
-			b.WriteString(helpStyle.Render("Enter to continue • Esc/Ctrl+C quit"))
+			b.WriteString(m.viewSyncStatus())
+			b.WriteString(helpStyle.Render("Enter to continue • Ctrl+S import your memberships • Esc/Ctrl+C quit"))
 		}
 	}
 
@@ -1080,6 +1799,67 @@ func (m Model) viewInput() string {
 	return b.String()
 }
 
+// renderTitle renders the title bar, naming the account profile when one is in use
+func (m Model) renderTitle() string {
+	if m.profile == "" {
+		return titleStyle.Render("🎨 Patreon Posts Viewer")
+	}
+	return titleStyle.Render("🎨 Patreon Posts Viewer · 👤 " + m.profile)
+}
+
+// reloadSummary describes what a config reload changed, e.g. "✓ Reloaded config: new cookies, 1 campaign added"
+func reloadSummary(msg ConfigReloadedMsg) string {
+	if msg.Err != nil {
+		return fmt.Sprintf("✗ Reloaded config, but: %v", msg.Err)
+	}
+	var changes []string
+	if msg.CookiesChanged {
+		changes = append(changes, "new cookies")
+	}
+	if msg.Campaigns != nil && len(msg.Campaigns.Added) > 0 {
+		changes = append(changes, fmt.Sprintf("%d campaign(s) added", len(msg.Campaigns.Added)))
+	}
+	if msg.Campaigns != nil && len(msg.Campaigns.Removed) > 0 {
+		changes = append(changes, fmt.Sprintf("%d campaign(s) removed", len(msg.Campaigns.Removed)))
+	}
+	if len(changes) == 0 {
+		return "✓ Reloaded config"
+	}
+	return "✓ Reloaded config: " + strings.Join(changes, ", ")
+}
+
+// viewSyncStatus shows the progress or outcome of a membership sync on the selection screen
+func (m Model) viewSyncStatus() string {
+	switch {
+	case m.syncing:
+		return fmt.Sprintf("%s Syncing memberships...\n\n", m.spinner.View())
+	case strings.HasPrefix(m.statusMessage, "✓"):
+		return successStyle.Render(m.statusMessage) + "\n\n"
+	case strings.HasPrefix(m.statusMessage, "✗"):
+		return errorStyle.Render(m.statusMessage) + "\n\n"
+	}
+	return ""
+}
+
+// membershipSummary describes a campaign's membership from the last sync, e.g. "Gold · 5.00"
+func membershipSummary(c db.SavedCampaign) string {
+	if c.MembershipSyncedAt == nil {
+		return ""
+	}
+	switch c.MembershipStatus {
+	case models.PatronStatusDeclined:
+		return "⚠ payment declined"
+	case models.PatronStatusFormer:
+		return "⚠ lapsed"
+	case models.PatronStatusActive:
+		if c.Tier != "" {
+			return fmt.Sprintf("%s · %s", c.Tier, models.FormatCents(c.PledgeCents))
+		}
+		return models.FormatCents(c.PledgeCents)
+	}
+	return "free"
+}
+
 func (m Model) viewLoading() string {
 	mainWidth := m.width - clipboardPanelWidth - 3
 	if mainWidth < 40 {
@@ -1088,9 +1868,11 @@ func (m Model) viewLoading() string {
 
 	var main strings.Builder
 
-	main.WriteString(titleStyle.Render("🎨 Patreon Posts Viewer"))
+	main.WriteString(m.renderTitle())
 	main.WriteString("\n\n")
 	main.WriteString(fmt.Sprintf("%s %s", m.spinner.View(), m.loadingMsg))
+	main.WriteString("\n")
+	main.WriteString(helpStyle.Render("esc cancel • q quit"))
 
 	// Render clipboard panel
 	clipboardPanel := m.renderClipboardPanel(m.height, 3)
@@ -1115,24 +1897,23 @@ func (m Model) viewList() string {
 	// Build main content
 	var main strings.Builder
 
-	main.WriteString(titleStyle.Render("🎨 Patreon Posts Viewer"))
+	main.WriteString(m.renderTitle())
 	main.WriteString("\n")
 	// Build status with pagination info
 	pageInfo := fmt.Sprintf("Page %d", m.currentPage)
 	// Check if next page is available (considering filter)
-	canGoNext := m.hasMorePages && m.nextCursor != ""
-	if m.publishedAfter != "" && len(m.posts) < 20 {
-		canGoNext = false
-	}
-	if canGoNext {
+	if m.hasMorePages && m.nextCursor != "" && !m.pastDate {
 		pageInfo += " →"
 	}
 	if m.currentPage > 1 {
 		pageInfo = "← " + pageInfo
 	}
 	pageInfo += fmt.Sprintf(" (%d posts)", len(m.posts))
-	if m.publishedAfter != "" {
-		pageInfo += fmt.Sprintf(" • 📅 after %s", m.publishedAfter)
+	if after := m.afterDate(); after != "" {
+		pageInfo += fmt.Sprintf(" • 📅 after %s", after)
+	}
+	if m.campaign.Filter.Active() {
+		pageInfo += " • 🔎 filtered"
 	}
 	// Build campaign display with name if available
 	campaignDisplay := m.campaignID
@@ -1255,7 +2036,11 @@ func (m Model) viewDetails() string {
 	main.WriteString("\n\n")
 	main.WriteString(m.viewport.View())
 	main.WriteString("\n")
-	main.WriteString(helpStyle.Render("↑/k ↓/j nav links • a add • A add all • c copy • esc back • q quit"))
+	help := "↑/k ↓/j nav links • a add • A add all • C comments • d download files • c copy • esc back • q quit"
+	if m.downloading != "" {
+		help = "⬇ downloading files... • " + strings.Replace(help, "d download files", "d cancel download", 1)
+	}
+	main.WriteString(helpStyle.Render(help))
 
 	// Render clipboard panel (2 lines padding to align with title)
 	clipboardPanel := m.renderClipboardPanel(m.height, 3)
@@ -1278,43 +2063,101 @@ func (m Model) renderDetailsContent() string {
 	b.WriteString(headerStyle.Render(m.postDetails.Title))
 	b.WriteString("\n\n")
 
-	// YouTube Links section
-	if len(m.postDetails.YouTubeLinks) > 0 {
-		b.WriteString(youtubeStyle.Render("📺 YouTube Links"))
+	// Provider links section, grouped by provider
+	if len(m.postDetails.ProviderLinks) > 0 {
+		b.WriteString(headerStyle.Render("🔗 Links"))
 		b.WriteString(" (use ↑/↓ to select, 'a' to add)\n")
-		for i, link := range m.postDetails.YouTubeLinks {
-			// Check if link is in clipboard
-			inClipboard := false
-			for _, clipLink := range m.clipboardLinks {
-				if clipLink == link {
-					inClipboard = true
-					break
-				}
+		for i, link := range m.postDetails.ProviderLinks {
+			if i == 0 || m.postDetails.ProviderLinks[i-1].Provider != link.Provider {
+				b.WriteString(providerHeading(link.Provider))
+				b.WriteString("\n")
 			}
 
 			prefix := "  "
 			suffix := ""
-			if inClipboard {
+			if m.inClipboard(link.URL) {
 				suffix = " ✓"
 			}
 
 			if i == m.linkCursor {
-				b.WriteString(linkSelectedStyle.Render(fmt.Sprintf("%s▶ %s%s", prefix, link, suffix)))
+				b.WriteString(linkSelectedStyle.Render(fmt.Sprintf("%s▶ %s%s", prefix, link.URL, suffix)))
 			} else {
-				b.WriteString(fmt.Sprintf("%s  %s%s", prefix, urlStyle.Render(link), cachedStyle.Render(suffix)))
+				b.WriteString(fmt.Sprintf("%s  %s%s", prefix, urlStyle.Render(link.URL), cachedStyle.Render(suffix)))
+			}
+			if note := youtubeNote(link); note != "" {
+				b.WriteString(" ")
+				b.WriteString(typeStyle.Render(note))
 			}
 			b.WriteString("\n")
 		}
 		b.WriteString("\n")
 	} else {
-		b.WriteString(notCachedStyle.Render("No YouTube links found"))
+		b.WriteString(notCachedStyle.Render("No video or audio links found"))
+		b.WriteString("\n\n")
+	}
+
+	// Every hyperlink and embed in the post, selectable after the provider links
+	if len(m.postDetails.Links) > 0 {
+		b.WriteString(headerStyle.Render("🌐 All Links"))
+		b.WriteString("\n")
+		offset := len(m.postDetails.ProviderLinks)
+		for i, link := range m.postDetails.Links {
+			suffix := ""
+			if m.inClipboard(link.URL) {
+				suffix = " ✓"
+			}
+			if offset+i == m.linkCursor {
+				b.WriteString(linkSelectedStyle.Render(fmt.Sprintf("  ▶ %s%s", link.URL, suffix)))
+			} else {
+				b.WriteString(fmt.Sprintf("    %s%s", urlStyle.Render(link.URL), cachedStyle.Render(suffix)))
+			}
+			if label := linkLabel(link); label != "" {
+				b.WriteString(" ")
+				b.WriteString(typeStyle.Render(label))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	// Comments pane, toggled with C
+	if m.showComments {
+		b.WriteString(m.renderComments(len(m.postDetails.ProviderLinks) + len(m.postDetails.Links)))
+		b.WriteString("\n")
+	} else if count := m.commenterCount(); count > 0 {
+		b.WriteString(typeStyle.Render(fmt.Sprintf("💬 %d commenter(s) • press C to show comments", count)))
 		b.WriteString("\n\n")
 	}
 
+	// Files attached to the post
+	if len(m.postDetails.Media) > 0 {
+		b.WriteString(headerStyle.Render("📎 Attachments & Media"))
+		b.WriteString("\n")
+		for _, file := range m.postDetails.Media {
+			name := file.FileName
+			if name == "" {
+				name = "(unnamed " + file.Kind + ")"
+			}
+			b.WriteString(fmt.Sprintf("    %s", normalStyle.Render(name)))
+			if details := mediaLabel(file); details != "" {
+				b.WriteString(" ")
+				b.WriteString(typeStyle.Render(details))
+			}
+			if m.isDownloaded(file) {
+				b.WriteString(cachedStyle.Render(" ✓ saved"))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
 	// Description section
 	b.WriteString(headerStyle.Render("📝 Description"))
 	b.WriteString("\n")
-	if m.postDetails.Description != "" {
+	if m.postDetails.Content != "" {
+		// Render the HTML with paragraphs, lists and link references intact
+		b.WriteString(renderDocument(htmltext.Parse(m.postDetails.Content), m.viewport.Width-4))
+	} else if m.postDetails.Description != "" {
 		// Word wrap the description
 		wrapped := wordWrap(m.postDetails.Description, m.viewport.Width-4)
 		b.WriteString(descriptionStyle.Render(wrapped))
@@ -1326,20 +2169,287 @@ func (m Model) renderDetailsContent() string {
 	return b.String()
 }
 
+// youtubeNote describes the start offset or kind of a YouTube link, e.g. "from 12:34"
+func youtubeNote(link models.ProviderLink) string {
+	if link.Provider != links.YouTube {
+		return ""
+	}
+	yt, ok := links.ParseYouTubeURL(link.URL)
+	if !ok {
+		return ""
+	}
+
+	var notes []string
+	switch yt.Kind {
+	case models.YouTubePlaylist, models.YouTubeChannel:
+		notes = append(notes, string(yt.Kind))
+	}
+	if yt.StartSeconds > 0 {
+		offset := time.Duration(yt.StartSeconds) * time.Second
+		if offset >= time.Hour {
+			notes = append(notes, fmt.Sprintf("from %d:%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60, yt.StartSeconds%60))
+		} else {
+			notes = append(notes, fmt.Sprintf("from %d:%02d", int(offset.Minutes()), yt.StartSeconds%60))
+		}
+	}
+	if yt.Kind != models.YouTubePlaylist && yt.PlaylistID != "" {
+		notes = append(notes, "in playlist")
+	}
+	return strings.Join(notes, " • ")
+}
+
+// linkLabel describes where a link came from and what it says, e.g. "embed: YouTube"
+func linkLabel(link models.Link) string {
+	text := link.Text
+	if len(text) > 40 {
+		text = text[:37] + "..."
+	}
+	if link.Source == models.LinkSourceEmbed {
+		label := "embed"
+		if link.Provider != "" {
+			label += ": " + link.Provider
+		}
+		if text != "" {
+			label += " • " + text
+		}
+		return label
+	}
+	if text != "" {
+		return "“" + text + "”"
+	}
+	return ""
+}
+
+// commenterCount returns the number of commenters on the post in the details view, if known
+func (m Model) commenterCount() int {
+	if m.cursor < len(m.posts) && m.postDetails != nil && m.posts[m.cursor].ID == m.postDetails.ID {
+		return m.posts[m.cursor].CommenterCount
+	}
+	return 0
+}
+
+// renderComments renders the comments pane with threaded replies. Links in comments are
+// selectable; offset is the selectable index of the first one.
+func (m Model) renderComments(offset int) string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("💬 Comments"))
+	b.WriteString("\n")
+
+	if m.comments == nil {
+		if m.commentsLoading {
+			b.WriteString(notCachedStyle.Render("  Loading comments..."))
+		} else {
+			b.WriteString(notCachedStyle.Render("  Comments not loaded"))
+		}
+		b.WriteString("\n")
+		return b.String()
+	}
+	if len(m.comments.Comments) == 0 {
+		b.WriteString(notCachedStyle.Render("  No comments yet"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	index := offset
+	var render func(comments []models.Comment, depth int)
+	render = func(comments []models.Comment, depth int) {
+		indent := strings.Repeat("  ", depth+1)
+		for _, comment := range comments {
+			author := comment.Author
+			if author == "" {
+				author = "Unknown"
+			}
+			b.WriteString(indent)
+			b.WriteString(normalStyle.Bold(true).Render(author))
+			if comment.IsByCreator {
+				b.WriteString(" ")
+				b.WriteString(providerStyle.Render("creator"))
+			}
+			meta := comment.CreatedAt.Format("2006-01-02")
+			if comment.VoteSum != 0 {
+				meta += fmt.Sprintf(" • %+d", comment.VoteSum)
+			}
+			b.WriteString(" ")
+			b.WriteString(typeStyle.Render(meta))
+			b.WriteString("\n")
+
+			wrapped := wordWrap(comment.Body, m.viewport.Width-4-len(indent))
+			for _, line := range strings.Split(wrapped, "\n") {
+				b.WriteString(indent)
+				b.WriteString(descriptionStyle.Render(line))
+				b.WriteString("\n")
+			}
+
+			for _, link := range comment.Links {
+				suffix := ""
+				if m.inClipboard(link.URL) {
+					suffix = " ✓"
+				}
+				if index == m.linkCursor {
+					b.WriteString(linkSelectedStyle.Render(fmt.Sprintf("%s▶ %s%s", indent, link.URL, suffix)))
+				} else {
+					b.WriteString(fmt.Sprintf("%s  %s%s", indent, urlStyle.Render(link.URL), cachedStyle.Render(suffix)))
+				}
+				b.WriteString("\n")
+				index++
+			}
+
+			render(comment.Replies, depth+1)
+			if len(comment.Replies) < comment.ReplyCount {
+				b.WriteString(indent)
+				b.WriteString(notCachedStyle.Render(fmt.Sprintf("  … %d more repl(ies) on Patreon", comment.ReplyCount-len(comment.Replies))))
+				b.WriteString("\n")
+			}
+		}
+	}
+	render(m.comments.Comments, 0)
+
+	switch {
+	case m.commentsLoading:
+		b.WriteString(notCachedStyle.Render("  Loading more comments..."))
+		b.WriteString("\n")
+	case m.comments.HasMore:
+		b.WriteString(helpStyle.Render("  M load more comments"))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// isDownloaded reports whether a file has been recorded as downloaded
+func (m Model) isDownloaded(file models.MediaFile) bool {
+	if m.database == nil {
+		return false
+	}
+	existing, err := m.database.GetDownload(file.ID)
+	return err == nil && existing != nil
+}
+
+// mediaLabel describes a file's kind, size and mime type, e.g. "audio • 12.3 MB • audio/mpeg"
+func mediaLabel(file models.MediaFile) string {
+	parts := []string{file.Kind}
+	if file.SizeBytes > 0 {
+		parts = append(parts, download.FormatSize(file.SizeBytes))
+	}
+	if file.MimeType != "" {
+		parts = append(parts, file.MimeType)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// providerHeading renders the group heading for a link provider
+func providerHeading(provider string) string {
+	if provider == links.YouTube {
+		return youtubeStyle.Render("📺 " + links.DisplayName(provider))
+	}
+	return providerStyle.Render("🔗 " + links.DisplayName(provider))
+}
+
 func (m Model) viewError() string {
 	var b strings.Builder
 
-	b.WriteString(titleStyle.Render("🎨 Patreon Posts Viewer"))
+	b.WriteString(m.renderTitle())
 	b.WriteString("\n\n")
 	b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
-	b.WriteString("\n\n")
-	b.WriteString(helpStyle.Render("r retry • esc back • q quit"))
+	b.WriteString("\n")
+
+	if hint := errorHint(m.err); hint != "" {
+		b.WriteString(descriptionStyle.Render(wordWrap(hint, m.width-6)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if m.pastingCookies {
+		b.WriteString("Paste the Cookie header from a logged-in browser session:\n\n")
+		b.WriteString(inputStyle.Render(m.cookieInput.View()))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Enter to retry with new cookies • Esc cancel"))
+		return b.String()
+	}
+
+	helpText := "r retry • esc back • q quit"
+	if errors.Is(m.err, api.ErrUnauthorized) {
+		helpText = "p paste new cookies • " + helpText
+	}
+	b.WriteString(helpStyle.Render(helpText))
 
 	return b.String()
 }
 
-// wordWrap wraps text to the specified width
+// errorHint suggests what the user can do about an API error
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, api.ErrUnauthorized):
+		return "Your session_id cookie has expired or is missing. Press p to paste a new Cookie header, " +
+			"or update the cookies in your config file."
+	case errors.Is(err, api.ErrForbidden):
+		return "Patreon refused access. Your membership tier may not include this content, " +
+			"or your cookies belong to a different account."
+	case errors.Is(err, api.ErrNotFound):
+		return "Patreon could not find this campaign or post. Check that the campaign ID is correct."
+	case errors.Is(err, api.ErrRateLimited):
+		return "Patreon is rate limiting requests. Wait a few minutes, then press r to retry."
+	case errors.Is(err, api.ErrDecode):
+		return "Patreon returned data in an unexpected format. The API may have changed; " +
+			"check for an updated version of this tool."
+	}
+	return ""
+}
+
+// renderDocument renders parsed post content with styled headings, lists, quotes and link footnotes
+func renderDocument(doc *htmltext.Document, width int) string {
+	if width <= 0 {
+		width = 80
+	}
+	var b strings.Builder
+
+	for i, block := range doc.Blocks {
+		if i > 0 && !(block.Kind == htmltext.ListItem && doc.Blocks[i-1].Kind == htmltext.ListItem) {
+			b.WriteString("\n")
+		}
+
+		switch block.Kind {
+		case htmltext.Heading:
+			b.WriteString(contentHeadingStyle.Render(wordWrap(block.Text, width-2)))
+		case htmltext.ListItem:
+			indent := strings.Repeat("  ", block.Level-1)
+			marker := indent + block.Marker + " "
+			body := wordWrap(block.Text, width-2-len(marker))
+			// Hang continuation lines under the item text
+			body = strings.ReplaceAll(body, "\n", "\n"+strings.Repeat(" ", len(marker)))
+			b.WriteString(descriptionStyle.Render(listMarkerStyle.Render(marker) + body))
+		case htmltext.Quote:
+			b.WriteString(quoteStyle.Render(wordWrap(block.Text, width-4)))
+		case htmltext.Preformatted:
+			b.WriteString(descriptionStyle.Render(block.Text))
+		case htmltext.Rule:
+			b.WriteString(notCachedStyle.Render("  " + strings.Repeat("─", min(width-2, 40))))
+		default:
+			b.WriteString(descriptionStyle.Render(wordWrap(block.Text, width-2)))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(doc.Links) > 0 {
+		b.WriteString("\n")
+		for i, link := range doc.Links {
+			b.WriteString(fmt.Sprintf("  %s %s\n", footnoteStyle.Render(fmt.Sprintf("[%d]", i+1)), urlStyle.Render(link.URL)))
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// wordWrap wraps text to the specified width, keeping existing line breaks
 func wordWrap(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = wrapLine(line, width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapLine wraps a single line of text to the specified width
+func wrapLine(text string, width int) string {
 	if width <= 0 {
 		width = 80
 	}
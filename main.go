@@ -1,62 +1,167 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"patreon-posts/internal/api"
+	"patreon-posts/internal/archive"
 	"patreon-posts/internal/cli"
 	"patreon-posts/internal/config"
+	"patreon-posts/internal/cookies"
 	"patreon-posts/internal/db"
+	"patreon-posts/internal/links"
 	"patreon-posts/internal/ui"
 )
 
 func main() {
 	// Parse command line flags
 	cookiesFlag := flag.String("cookies", "", "Patreon session cookies (or set via config file)")
-	configPath := flag.String("config", "", "Path to config file (default: ~/.patreon-posts.json)")
-	dbPath := flag.String("db", "", "Path to SQLite database (default: ~/.patreon-posts.db)")
+	cookiesFromFlag := flag.String("cookies-from", "", "Read cookies from firefox, chrome, chromium or a cookies.txt file")
+	configPath := flag.String("config", "", "Path to config file (default: $XDG_CONFIG_HOME/patreon-posts/config.json)")
+	profileFlag := flag.String("profile", "", "Use the named account profile from the config file")
+	dbPath := flag.String("db", "", "Path to SQLite database (default: $XDG_DATA_HOME/patreon-posts/patreon-posts.db)")
 	afterFlag := flag.String("after", "", "Only show posts published after this date (YYYY-MM-DD)")
-	extractLinks := flag.Bool("extract-links", false, "Extract YouTube links from all campaigns and copy to clipboard")
+	apiURLFlag := flag.String("api-url", "", "Patreon API base URL (default: https://www.patreon.com/api)")
+	proxyFlag := flag.String("proxy", "", "HTTP(S) proxy URL for API requests")
+	extractLinks := flag.Bool("extract-links", false, "Print the links of every enabled provider from all campaigns")
+	addCampaign := flag.String("add-campaign", "", "Add a campaign by ID, creator name or Patreon URL, then exit")
+	allowInsecureConfig := flag.Bool("allow-insecure-config", false, "Load a config file with cookies even if other users can read it")
+	debugLog := flag.String("debug-log", "", "Append debug messages, such as cookie updates, to this file")
+	skipSessionCheck := flag.Bool("skip-session-check", false, "Don't check the session cookies with Patreon at startup")
+	syncMemberships := flag.Bool("sync-memberships", false, "Import the campaigns you're a member of, then exit")
 	flag.Parse()
 
-	// Determine config path
-	cfgPath := *configPath
-	if cfgPath == "" {
-		var err error
-		cfgPath, err = config.DefaultConfigPath()
+	// Determine config path: flag, environment, then the XDG default
+	cfgFile := cli.ConfigFile{Path: *configPath, Source: "flag --config"}
+	if cfgFile.Path == "" {
+		cfgFile = cli.ConfigFile{Path: os.Getenv(config.EnvPrefix + "CONFIG"), Source: "env " + config.EnvPrefix + "CONFIG"}
+	}
+	if cfgFile.Path == "" {
+		// Versions before XDG support kept the config in the home directory
+		moved, err := config.MigrateLegacyConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else if moved != "" {
+			fmt.Printf("📦 Moved your config file to %s\n", moved)
+		}
+		cfgFile.Source = config.SourceDefault
+		cfgFile.Path, err = config.DefaultConfigPath()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 	}
+	cfgFile.Path = config.ExpandHome(cfgFile.Path)
+	cfgPath := cfgFile.Path
+
+	// Checking the file comes before loading it, so load errors are reported rather than fatal
+	if flag.Arg(0) == "config" && flag.Arg(1) == "check" {
+		if !cli.CheckConfig(cfgFile) {
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Load config
-	cfg, err := config.Load(cfgPath)
+	loader := configLoader{
+		path:     cfgPath,
+		insecure: *allowInsecureConfig,
+		profile:  *profileFlag,
+		flags: []flagSetting{
+			{"cookies", "cookies", *cookiesFlag},
+			{"cookie_source", "cookies-from", *cookiesFromFlag},
+			{"published_after", "after", *afterFlag},
+			{"api_base_url", "api-url", *apiURLFlag},
+			{"proxy_url", "proxy", *proxyFlag},
+			{"db_path", "db", *dbPath},
+		},
+	}
+	if loader.profile == "" {
+		loader.profile = os.Getenv(config.EnvPrefix + "PROFILE")
+	}
+	cfg, err := loader.read()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Use cookies from flag or config
-	cookies := *cookiesFlag
-	if cookies == "" {
-		cookies = cfg.Cookies
+	// Refuse to run with a broken config file, though 'config show' still works; warnings are only shown
+	if problems := cfg.Validate(); len(problems) > 0 && flag.Arg(0) != "config" {
+		fmt.Fprintf(os.Stderr, "Problems in %s:\n", cfgPath)
+		cli.PrintProblems(os.Stderr, problems)
+		if problems.HasErrors() {
+			fmt.Fprintln(os.Stderr, "Fix the errors above, then run 'patreon-posts config check' to confirm.")
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+
+	// Apply the selected account profile, environment variables and flags
+	cfg, err = loader.apply(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.Profile != "" {
+		fmt.Printf("👤 Using profile %s\n", cfg.Profile)
 	}
 
-	// Determine database path
-	databasePath := *dbPath
-	if databasePath == "" {
-		var err error
-		databasePath, err = db.DefaultDBPath()
+	// Determine database path; profiles keep separate caches and session cookies
+	databasePath := config.ExpandHome(cfg.DBPath)
+	defaultDB := databasePath == ""
+	if defaultDB {
+		databasePath, err = config.ProfileDBPath(cfg.Profile)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 	}
 
+	// Handle config subcommands, which don't need cookies or the database
+	if flag.Arg(0) == "config" {
+		switch flag.Arg(1) {
+		case "show":
+			cli.ShowConfig(cfg, cfgFile, databasePath)
+		default:
+			fmt.Fprintln(os.Stderr, "Usage: patreon-posts config show|check")
+			os.Exit(2)
+		}
+		return
+	}
+
+	// Load the session cookies
+	cookieHeader, cookieSource, err := loadCookies(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if cookieSource != "" {
+		fmt.Printf("🍪 Loaded cookies from %s\n", cookieSource)
+	}
+
+	// Move a database left in the home directory by earlier versions
+	if defaultDB {
+		moved, err := config.MigrateLegacyDB(cfg.Profile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else if moved != "" {
+			fmt.Printf("📦 Moved your database to %s\n", moved)
+			databasePath = moved
+		}
+	}
+
 	// Open database
 	database, err := db.Open(databasePath)
 	if err != nil {
@@ -65,38 +170,353 @@ func main() {
 	}
 	defer database.Close()
 
-	// Seed campaigns from config if present
-	for _, campaign := range cfg.Campaigns {
-		database.SaveCampaign(campaign.ID, campaign.Name)
+	// The config file's campaign list wins over the database
+	reconciled, err := cli.ReconcileCampaigns(cfg, database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading campaigns: %v\n", err)
+		os.Exit(1)
 	}
-
-	// Use published_after from flag or config
-	publishedAfter := *afterFlag
-	if publishedAfter == "" {
-		publishedAfter = cfg.PublishedAfter
+	if len(reconciled.Removed) > 0 {
+		fmt.Printf("🗑️  Removed %d campaign(s) no longer in %s: %s\n", len(reconciled.Removed), cfgPath, strings.Join(reconciled.Removed, ", "))
 	}
 
+	publishedAfter := cfg.PublishedAfter
+
 	// Warn if no cookies provided
-	if cookies == "" {
+	if cookieHeader == "" {
 		fmt.Println("⚠️  No cookies provided. You may not be able to view patron-only content.")
-		fmt.Printf("   Set cookies in %s or use --cookies flag.\n\n", cfgPath)
+		fmt.Printf("   Set cookies, cookies_file or cookie_source in %s, or use the --cookies or --cookies-from flag.\n\n", cfgPath)
+	}
+
+	// Open the debug log, if requested
+	var logger *log.Logger
+	if *debugLog != "" {
+		logFile, err := os.OpenFile(*debugLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening debug log: %v\n", err)
+			os.Exit(1)
+		}
+		defer logFile.Close()
+		logger = log.New(logFile, "", log.LstdFlags)
+	}
+
+	// Build the API client shared by the extractor and the TUI.
+	// Cookies Patreon rotates are kept in the database so the session outlives them.
+	clientOpts := api.ClientOptions{
+		BaseURL:     cfg.APIBaseURL,
+		Cookies:     cookieHeader,
+		CookieStore: database,
+		Logger:      logger,
+		Timeout:     time.Duration(cfg.GetRequestTimeoutMs()) * time.Millisecond,
+		UserAgent:   cfg.UserAgent,
+		ProxyURL:    cfg.ProxyURL,
+		Links:       links.NewDefaultRegistry(cfg.LinkProviders),
+		RateLimit: &api.RateLimit{
+			MinDelay: time.Duration(cfg.GetRequestDelayMinMs()) * time.Millisecond,
+			MaxDelay: time.Duration(cfg.GetRequestDelayMaxMs()) * time.Millisecond,
+			Burst:    cfg.GetRequestBurst(),
+		},
+		Retry: &api.RetryPolicy{
+			MaxRetries: cfg.GetMaxRetries(),
+			BaseDelay:  time.Duration(cfg.GetRetryBaseDelayMs()) * time.Millisecond,
+			MaxDelay:   time.Duration(cfg.GetRetryMaxDelayMs()) * time.Millisecond,
+		},
+	}
+	client, err := api.NewClient(clientOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating API client: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Handle add-campaign mode
+	if *addCampaign != "" {
+		ctx, stop := interruptContext()
+		err := cli.AddCampaign(ctx, cfgPath, cfg, client, database, *addCampaign)
+		stop()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error adding campaign: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Handle sync-memberships mode
+	if *syncMemberships {
+		fmt.Println("🔄 Fetching your memberships...")
+		ctx, stop := interruptContext()
+		err := cli.SyncMemberships(ctx, cfgPath, cfg, client, database)
+		stop()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error syncing memberships: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Handle subcommands
+	switch flag.Arg(0) {
+	case "":
+	case "whoami":
+		ctx, stop := interruptContext()
+		err := cli.WhoAmI(ctx, client)
+		stop()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case "download":
+		downloadFlags := flag.NewFlagSet("download", flag.ExitOnError)
+		dirFlag := downloadFlags.String("dir", cfg.GetDownloadDir(), "Directory to save files under")
+		campaignFlag := downloadFlags.String("campaign", "", "Only download from this campaign ID (default: all configured campaigns)")
+		postFlag := downloadFlags.String("post", "", "Only download the files of this post ID")
+		downloadFlags.Parse(flag.Args()[1:])
+
+		ctx, stop := interruptContext()
+		checkSession(ctx, client, cookieHeader, *skipSessionCheck)
+		err := cli.DownloadMedia(ctx, cfg, client, database, cli.DownloadOptions{
+			Dir:        *dirFlag,
+			CampaignID: *campaignFlag,
+			PostID:     *postFlag,
+			AfterDate:  publishedAfter,
+		})
+		stop()
+		if errors.Is(err, context.Canceled) {
+			os.Exit(130)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error downloading files: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case "archive":
+		archiveFlags := flag.NewFlagSet("archive", flag.ExitOnError)
+		dirFlag := archiveFlags.String("dir", cfg.GetArchiveDir(), "Directory to write the archive to")
+		formatFlag := archiveFlags.String("format", "markdown", "Archive format: markdown or html")
+		campaignFlag := archiveFlags.String("campaign", "", "Only archive this campaign ID (default: all saved campaigns)")
+		syncFlag := archiveFlags.Bool("sync", false, "Fetch every post from Patreon first, not just cached ones")
+		archiveFlags.Parse(flag.Args()[1:])
+
+		format, err := archive.ParseFormat(*formatFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+
+		ctx, stop := interruptContext()
+		if *syncFlag {
+			checkSession(ctx, client, cookieHeader, *skipSessionCheck)
+		}
+		err = cli.ArchivePosts(ctx, cfg, client, database, cli.ArchiveOptions{
+			Dir:        *dirFlag,
+			Format:     format,
+			CampaignID: *campaignFlag,
+			Sync:       *syncFlag,
+			AfterDate:  publishedAfter,
+		})
+		stop()
+		if errors.Is(err, context.Canceled) {
+			os.Exit(130)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error archiving posts: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n", flag.Arg(0))
+		os.Exit(2)
 	}
 
 	// Handle extract-links mode
 	if *extractLinks {
-		if err := cli.ExtractYouTubeLinks(cfg, database, publishedAfter); err != nil {
+		ctx, stop := interruptContext()
+		checkSession(ctx, client, cookieHeader, *skipSessionCheck)
+		err := cli.ExtractLinks(ctx, cfg, client, database)
+		stop()
+		if errors.Is(err, context.Canceled) {
+			os.Exit(130)
+		}
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error extracting links: %v\n", err)
 			os.Exit(1)
 		}
 		return
 	}
 
+	// Give the user a chance to read a session warning before the TUI takes over the screen
+	ctx, stop := interruptContext()
+	sessionOK := checkSession(ctx, client, cookieHeader, *skipSessionCheck)
+	interrupted := ctx.Err() != nil
+	stop()
+	if interrupted {
+		os.Exit(130)
+	}
+	if !sessionOK {
+		fmt.Print("Press Enter to continue anyway, or Ctrl+C to quit...")
+		bufio.NewReader(os.Stdin).ReadString('\n')
+	}
+
 	// Create and run the TUI
-	model := ui.NewModel(cookies, database, publishedAfter)
+	model := ui.NewModel(ui.Options{
+		Client:         client,
+		Database:       database,
+		PublishedAfter: publishedAfter,
+		DownloadDir:    cfg.GetDownloadDir(),
+		Profile:        cfg.Profile,
+		Config:         cfg,
+		ConfigPath:     cfgPath,
+	})
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
+	// Reload the config file when it's edited while the TUI runs
+	watcher := config.Watch(cfgPath)
+	defer watcher.Close()
+	reloader := &configReloader{loader: loader, client: client, database: database, cookies: cookieHeader}
+	go func() {
+		for range watcher.Changes() {
+			p.Send(reloader.reload())
+		}
+	}()
+
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running app: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// flagSetting is a config key given on the command line
+type flagSetting struct {
+	key   string // Config key the flag sets
+	flag  string // Flag name, without dashes
+	value string // Empty when the flag wasn't given
+}
+
+// configLoader reads the config file and layers the profile, environment variables and
+// flags over it, so a reload resolves the config exactly as startup did
+type configLoader struct {
+	path     string
+	insecure bool   // Load a config file other users can read
+	profile  string // Profile from the flag or environment, if any
+	flags    []flagSetting
+}
+
+// read loads the config file as it is on disk
+func (l configLoader) read() (*config.Config, error) {
+	if l.insecure {
+		return config.LoadInsecure(l.path)
+	}
+	return config.Load(l.path)
+}
+
+// apply returns cfg with the selected profile applied, then environment variables, then
+// flags, each overriding the ones before
+func (l configLoader) apply(cfg *config.Config) (*config.Config, error) {
+	cfg, err := cfg.WithProfile(l.profile)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	for _, f := range l.flags {
+		if f.value != "" {
+			if err := cfg.Set(f.key, f.value, "flag --"+f.flag); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// loadCookies returns the Cookie header from the cookie source, secret provider or config,
+// in that order, and the name of the browser or file it was read from, if any. A cookie
+// option set by a flag or environment variable has cleared the others.
+func loadCookies(cfg *config.Config) (header, source string, err error) {
+	if cfg.CookieSource != "" {
+		header, src, err := cookies.Load(cfg.CookieSource)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to load cookies: %w", err)
+		}
+		if header != "" {
+			return header, src.Name(), nil
+		}
+	}
+	provider, err := cfg.CookieProvider()
+	if err != nil {
+		return "", "", err
+	}
+	if provider != nil {
+		header, err := provider.Secret()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to load cookies from %s: %w", provider.Name(), err)
+		}
+		if header != "" {
+			return header, "", nil
+		}
+	}
+	return cfg.Cookies, "", nil
+}
+
+// configReloader applies edits to the config file while the TUI runs: it swaps the
+// client's cookies if they changed and reconciles the saved campaigns with the file
+type configReloader struct {
+	loader   configLoader
+	client   *api.Client
+	database *db.Database
+	cookies  string // Cookie header the client was last given
+}
+
+// reload loads the edited config file, returning the message that hands it to the TUI.
+// A config file with errors is reported and the running config is kept.
+func (r *configReloader) reload() ui.ConfigReloadedMsg {
+	cfg, err := r.loader.read()
+	if err != nil {
+		return ui.ConfigReloadedMsg{Err: err}
+	}
+	if err := cfg.Validate().Err(); err != nil {
+		return ui.ConfigReloadedMsg{Err: err}
+	}
+	cfg, err = r.loader.apply(cfg)
+	if err != nil {
+		return ui.ConfigReloadedMsg{Err: err}
+	}
+	header, _, err := loadCookies(cfg)
+	if err != nil {
+		return ui.ConfigReloadedMsg{Err: err}
+	}
+
+	msg := ui.ConfigReloadedMsg{Config: cfg}
+	// Keep the session, and any cookies Patreon has rotated since, unless the cookies changed
+	if header != r.cookies {
+		r.client.SetCookies(header)
+		r.cookies = header
+		msg.CookiesChanged = true
+	}
+	msg.Campaigns, err = cli.ReconcileCampaigns(cfg, r.database)
+	if err != nil {
+		msg.Err = err
+	}
+	return msg
+}
+
+// checkSession runs the startup session check unless it was skipped or there are no
+// cookies to check, returning false if Patreon rejected the session
+func checkSession(ctx context.Context, client *api.Client, cookieHeader string, skip bool) bool {
+	if skip || cookieHeader == "" {
+		// Missing cookies were already warned about
+		return true
+	}
+	return cli.CheckSession(ctx, client)
+}
+
+// interruptContext returns a context cancelled by Ctrl+C so long-running commands can stop
+// gracefully; a second Ctrl+C exits immediately
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}